@@ -0,0 +1,109 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+// addMessage is received on the "calendar.add" topic.
+type addMessage struct {
+	Module   string    `json:"module"`
+	Calendar string    `json:"calendar"`
+	Title    string    `json:"title"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	AllDay   bool      `json:"allDay"`
+}
+
+// handleAdd creates an event in a CalDAV calendar allowing quick add,
+// then reloads the calendar so the event is shown.
+func (m *Module) handleAdd(ctx context.Context, data []byte) {
+	var msg addMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		m.log.Error("Could not parse add message", "error", err.Error())
+		return
+	}
+	if msg.Module != "" && msg.Module != m.mod.Name() {
+		return
+	}
+
+	src, err := m.quickAddSource(msg.Calendar)
+	if err != nil {
+		m.log.Error("Could not add event", "error", err.Error())
+		return
+	}
+	evnt, err := newQuickAddEvent(msg)
+	if err != nil {
+		m.log.Error("Could not add event", "error", err.Error())
+		return
+	}
+
+	addCtx, cancel := context.WithTimeout(ctx, src.timeout)
+	err = src.api.(*calDAVSource).create(addCtx, evnt)
+	cancel()
+	if err != nil {
+		m.log.Error("Could not add event", "calendar", src.name(), "error", err.Error())
+		return
+	}
+
+	m.log.Info("Added event", "calendar", src.name(), "title", evnt.Summary)
+	m.load(ctx, src)
+	m.render()
+}
+
+// quickAddSource returns the named calendar allowing quick add, or the
+// first such calendar when no name is given.
+func (m *Module) quickAddSource(name string) (*source, error) {
+	for _, src := range m.sources {
+		if _, ok := src.api.(*calDAVSource); !ok || !src.cal.QuickAdd {
+			continue
+		}
+		if name == "" || name == src.name() {
+			return src, nil
+		}
+	}
+	if name != "" {
+		return nil, fmt.Errorf("calendar %q does not allow quick add", name)
+	}
+	return nil, errors.New("no calendar allows quick add")
+}
+
+// newQuickAddEvent returns the event to add. Timed events last an hour
+// and all day events a day when no end is given.
+func newQuickAddEvent(msg addMessage) (ical.Event, error) {
+	if msg.Title == "" || msg.Start.IsZero() {
+		return ical.Event{}, errors.New("title and start are required")
+	}
+
+	uid := make([]byte, 16)
+	if _, err := rand.Read(uid); err != nil {
+		return ical.Event{}, fmt.Errorf("generating uid: %w", err)
+	}
+
+	start, end := msg.Start, msg.End
+	switch {
+	case msg.AllDay:
+		start = startOfDay(start)
+		if !end.After(start) {
+			end = start.AddDate(0, 0, 1)
+		}
+	case !end.After(start):
+		end = start.Add(time.Hour)
+	}
+	return ical.Event{
+		UID:     hex.EncodeToString(uid) + "@glasslabs-calendar",
+		Summary: msg.Title,
+		Start:   start,
+		End:     end,
+		AllDay:  msg.AllDay,
+	}, nil
+}
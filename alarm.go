@@ -0,0 +1,127 @@
+package main
+
+import (
+	"sort"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+	"github.com/glasslabs/calendar/pkg/render"
+)
+
+// alarmTimes returns the sorted trigger times of the alarms of the event.
+// Absolute alarms are ignored for recurring events, as they would trigger
+// once for every instance.
+func alarmTimes(evnt ical.Event) []time.Time {
+	var times []time.Time
+	for _, alarm := range evnt.Alarms {
+		if !alarm.At.IsZero() && evnt.IsRecurring {
+			continue
+		}
+		times = append(times, alarm.Time(evnt))
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times
+}
+
+// alarms tracks the triggered alarms of events.
+type alarms struct {
+	timeout time.Duration
+
+	fired  map[string]time.Time
+	active []render.Reminder
+}
+
+func newAlarms(timeout time.Duration) *alarms {
+	return &alarms{
+		timeout: timeout,
+		fired:   map[string]time.Time{},
+	}
+}
+
+// alarmKey returns the key of an alarm of the event.
+func alarmKey(evnt render.Event, at time.Time) string {
+	return evnt.ID + "@" + at.UTC().Format(eventIDLayout)
+}
+
+// trigger activates the alarms of the events that are due, returning
+// the newly triggered reminders. Alarms that were due more than the
+// timeout ago are not triggered.
+func (a *alarms) trigger(events []render.Event, now time.Time) []render.Reminder {
+	for key, at := range a.fired {
+		if !at.After(now.Add(-a.timeout)) {
+			delete(a.fired, key)
+		}
+	}
+
+	var triggered []render.Reminder
+	for _, evnt := range events {
+		for _, at := range evnt.Alarms {
+			if at.After(now) || !at.After(now.Add(-a.timeout)) {
+				continue
+			}
+			key := alarmKey(evnt, at)
+			if _, ok := a.fired[key]; ok {
+				continue
+			}
+			a.fired[key] = at
+
+			triggered = append(triggered, render.Reminder{
+				Key:         key,
+				ID:          evnt.ID,
+				Title:       evnt.Title,
+				Description: evnt.Description,
+				Time:        evnt.Time,
+				IsAllDay:    evnt.IsAllDay,
+				Until:       now.Add(a.timeout),
+			})
+		}
+	}
+	a.active = append(a.active, triggered...)
+	return triggered
+}
+
+// expire removes the reminders shown for longer than the timeout,
+// returning true if any were removed.
+func (a *alarms) expire(now time.Time) bool {
+	res := a.active[:0]
+	for _, r := range a.active {
+		if r.Until.After(now) {
+			res = append(res, r)
+		}
+	}
+	expired := len(res) != len(a.active)
+	a.active = res
+	return expired
+}
+
+// acknowledge removes the reminder with the given key, returning true
+// if it was shown.
+func (a *alarms) acknowledge(key string) bool {
+	for i, r := range a.active {
+		if r.Key == key {
+			a.active = append(a.active[:i], a.active[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// next returns the time of the next alarm or reminder expiry after now,
+// or the zero time if there is none.
+func (a *alarms) next(events []render.Event, now time.Time) time.Time {
+	var next time.Time
+	earliest := func(t time.Time) {
+		if t.After(now) && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+	for _, evnt := range events {
+		for _, at := range evnt.Alarms {
+			earliest(at)
+		}
+	}
+	for _, r := range a.active {
+		earliest(r.Until)
+	}
+	return next
+}
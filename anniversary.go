@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/render"
+)
+
+// anniversary is a date counted down to, or the yearly anniversary of
+// which is counted once passed.
+type anniversary struct {
+	name string
+	date time.Time
+}
+
+func newAnniversaries(cfgs []Anniversary, tz *time.Location) ([]anniversary, error) {
+	annivs := make([]anniversary, 0, len(cfgs))
+	for i, cfg := range cfgs {
+		date, err := time.ParseInLocation("2006-01-02", cfg.Date, tz)
+		if err != nil {
+			return nil, fmt.Errorf("anniversary %d: parsing date: %w", i, err)
+		}
+		annivs = append(annivs, anniversary{name: cfg.Name, date: date})
+	}
+	return annivs, nil
+}
+
+// anniversaryEvents returns the countdowns to future dates, and the
+// anniversaries of past dates falling within the given number of days.
+func anniversaryEvents(annivs []anniversary, now time.Time, days int) []render.Event {
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+
+	var events []render.Event
+	for _, a := range annivs {
+		date := time.Date(a.date.Year(), a.date.Month(), a.date.Day(), 0, 0, 0, 0, now.Location())
+		if !date.Before(today) {
+			events = append(events, render.Event{
+				Title:    a.name + " — " + countdown(daysBetween(today, date)),
+				Time:     date,
+				IsAllDay: true,
+			})
+			continue
+		}
+
+		years := today.Year() - date.Year()
+		next := date.AddDate(years, 0, 0)
+		if next.Before(today) {
+			years++
+			next = date.AddDate(years, 0, 0)
+		}
+		if daysBetween(today, next) >= days {
+			continue
+		}
+		events = append(events, render.Event{
+			Title:    a.name + " — " + plural(years, "year"),
+			Time:     next,
+			IsAllDay: true,
+		})
+	}
+	return events
+}
+
+func countdown(days int) string {
+	switch days {
+	case 0:
+		return "today"
+	case 1:
+		return "tomorrow"
+	default:
+		return "in " + plural(days, "day")
+	}
+}
+
+func plural(n int, unit string) string {
+	s := strconv.Itoa(n) + " " + unit
+	if n != 1 {
+		s += "s"
+	}
+	return s
+}
+
+// daysBetween returns the number of calendar days from a to b.
+func daysBetween(a, b time.Time) int {
+	ua := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	ub := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(ub.Sub(ua) / (24 * time.Hour))
+}
@@ -0,0 +1,11 @@
+package main
+
+import "embed"
+
+var (
+	//go:embed assets/style.css
+	css []byte
+
+	//go:embed assets/themes/*.css
+	themes embed.FS
+)
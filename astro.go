@@ -0,0 +1,153 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+const (
+	julianUnixEpoch = 2440587.5
+	julianJ2000     = 2451545.0
+)
+
+// astro generates sunrise, sunset and moon phase events.
+type astro struct {
+	cfg Astro
+	tz  *time.Location
+}
+
+func (a astro) generate(start, end time.Time) []ical.Event {
+	var evnts []ical.Event
+	if a.cfg.Sunrise || a.cfg.Sunset {
+		y, m, d := start.In(a.tz).Date()
+		for day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC); day.Before(end); day = day.AddDate(0, 0, 1) {
+			rise, set, ok := sunTimes(day, a.cfg.Latitude, a.cfg.Longitude)
+			if !ok {
+				continue
+			}
+			if a.cfg.Sunrise {
+				evnts = appendAstro(evnts, "sunrise", "Sunrise", rise, start, end)
+			}
+			if a.cfg.Sunset {
+				evnts = appendAstro(evnts, "sunset", "Sunset", set, start, end)
+			}
+		}
+	}
+	if a.cfg.MoonPhases {
+		for _, p := range moonPhases(start, end) {
+			evnts = appendAstro(evnts, "moon", p.name, p.time, start, end)
+		}
+	}
+	return evnts
+}
+
+func appendAstro(evnts []ical.Event, kind, title string, t, start, end time.Time) []ical.Event {
+	if t.Before(start) || !t.Before(end) {
+		return evnts
+	}
+	return append(evnts, ical.Event{
+		UID:     kind + "-" + t.UTC().Format("20060102T1504") + "@glasslabs-calendar",
+		Summary: title,
+		Start:   t,
+		End:     t,
+	})
+}
+
+// sunTimes returns the sunrise and sunset on the given UTC day at the
+// location using the sunrise equation. It returns false when the sun
+// does not rise or set that day.
+func sunTimes(day time.Time, lat, lon float64) (rise, set time.Time, ok bool) {
+	n := math.Round(toJulian(day) - julianJ2000 + 0.5)
+	meanNoon := n - lon/360
+
+	m := normDeg(357.5291 + 0.98560028*meanNoon)
+	c := 1.9148*sinDeg(m) + 0.02*sinDeg(2*m) + 0.0003*sinDeg(3*m)
+	l := normDeg(m + c + 180 + 102.9372)
+	transit := julianJ2000 + meanNoon + 0.0053*sinDeg(m) - 0.0069*sinDeg(2*l)
+
+	sinDecl := sinDeg(l) * sinDeg(23.44)
+	cosDecl := math.Cos(math.Asin(sinDecl))
+	cosHour := (sinDeg(-0.833) - sinDeg(lat)*sinDecl) / (cosDeg(lat) * cosDecl)
+	if cosHour < -1 || cosHour > 1 {
+		return time.Time{}, time.Time{}, false
+	}
+	hour := math.Acos(cosHour) * 180 / math.Pi
+
+	return fromJulian(transit - hour/360), fromJulian(transit + hour/360), true
+}
+
+type moonPhase struct {
+	name string
+	time time.Time
+}
+
+var moonPhaseNames = [4]string{"New moon", "First quarter", "Full moon", "Last quarter"}
+
+// moonPhases returns the principal moon phases between start and end,
+// using the main periodic terms from Meeus, Astronomical Algorithms ch. 49.
+func moonPhases(start, end time.Time) []moonPhase {
+	const synodic = 29.530588861
+
+	var phases []moonPhase
+	k := math.Floor((toJulian(start) - 2451550.09766) / synodic)
+	for i := 0; ; i++ {
+		q := i % 4
+		t := fromJulian(moonPhaseJulian(k + float64(i/4) + float64(q)/4))
+		if !t.Before(end) {
+			return phases
+		}
+		if !t.Before(start) {
+			phases = append(phases, moonPhase{name: moonPhaseNames[q], time: t})
+		}
+	}
+}
+
+func moonPhaseJulian(k float64) float64 {
+	t := k / 1236.85
+	jde := 2451550.09766 + 29.530588861*k + 0.00015437*t*t
+
+	e := 1 - 0.002516*t
+	m := 2.5534 + 29.10535670*k
+	mp := 201.5643 + 385.81693528*k
+	f := 160.7108 + 390.67050284*k
+
+	switch k - math.Floor(k) {
+	case 0:
+		jde += -0.40720*sinDeg(mp) + 0.17241*e*sinDeg(m) + 0.01608*sinDeg(2*mp) + 0.01039*sinDeg(2*f) +
+			0.00739*e*sinDeg(mp-m) - 0.00514*e*sinDeg(mp+m) + 0.00208*e*e*sinDeg(2*m)
+	case 0.5:
+		jde += -0.40614*sinDeg(mp) + 0.17302*e*sinDeg(m) + 0.01614*sinDeg(2*mp) + 0.01043*sinDeg(2*f) +
+			0.00734*e*sinDeg(mp-m) - 0.00515*e*sinDeg(mp+m) + 0.00209*e*e*sinDeg(2*m)
+	default:
+		jde += -0.62801*sinDeg(mp) + 0.17172*e*sinDeg(m) - 0.01183*e*sinDeg(mp+m) + 0.00862*sinDeg(2*mp) +
+			0.00804*sinDeg(2*f) + 0.00454*e*sinDeg(mp-m) + 0.00204*e*e*sinDeg(2*m)
+		w := 0.00306 - 0.00038*e*cosDeg(m) + 0.00026*cosDeg(mp)
+		if k-math.Floor(k) == 0.25 {
+			jde += w
+		} else {
+			jde -= w
+		}
+	}
+	return jde
+}
+
+func toJulian(t time.Time) float64 {
+	return float64(t.Unix())/86400 + julianUnixEpoch
+}
+
+func fromJulian(jd float64) time.Time {
+	return time.Unix(int64(math.Round((jd-julianUnixEpoch)*86400)), 0)
+}
+
+func normDeg(d float64) float64 {
+	d = math.Mod(d, 360)
+	if d < 0 {
+		d += 360
+	}
+	return d
+}
+
+func sinDeg(d float64) float64 { return math.Sin(d * math.Pi / 180) }
+func cosDeg(d float64) float64 { return math.Cos(d * math.Pi / 180) }
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // Digest authentication is defined over MD5.
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+// Authentication types.
+const (
+	authNone   = "none"
+	authBasic  = "basic"
+	authBearer = "bearer"
+	authOAuth2 = "oauth2"
+	authDigest = "digest"
+)
+
+// apiAuthTypes are the authentication types supported by each calendar type.
+var apiAuthTypes = map[string][]string{
+	"":              {authBasic, authBearer, authOAuth2, authDigest},
+	calendarICloud:  {authBasic},
+	calendarGitHub:  {authBearer},
+	calendarJira:    {authBasic},
+	calendarTodoist: {authBearer},
+	calendarTrello:  {authBearer},
+}
+
+// validateAuth checks the authentication of the calendar.
+func validateAuth(cal Calendar) error {
+	auth := cal.Auth
+	if auth.Type == "" {
+		if auth.Username != "" || auth.Password != "" || auth.Token != "" || auth.Key != "" || auth.ClientID != "" {
+			return errors.New("auth type is required")
+		}
+		return nil
+	}
+
+	supported := auth.Type == authNone
+	for _, typ := range apiAuthTypes[cal.Type] {
+		supported = supported || typ == auth.Type
+	}
+	switch {
+	case auth.Type != authNone && auth.Type != authBasic && auth.Type != authBearer &&
+		auth.Type != authOAuth2 && auth.Type != authDigest:
+		return fmt.Errorf("unknown auth type %q", auth.Type)
+	case !supported:
+		return fmt.Errorf("auth type %q is not supported by %s calendars", auth.Type, cal.Type)
+	}
+
+	var missing []string
+	require := func(name, val string) {
+		if val == "" {
+			missing = append(missing, name)
+		}
+	}
+	switch auth.Type {
+	case authBasic, authDigest:
+		require("username", auth.Username)
+		require("password", auth.Password)
+	case authBearer:
+		require("token", auth.Token)
+		if cal.Type == calendarTrello {
+			require("key", auth.Key)
+		}
+	case authOAuth2:
+		require("tokenUrl", auth.TokenURL)
+		require("clientId", auth.ClientID)
+		require("clientSecret", auth.ClientSecret)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s auth requires %s", auth.Type, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// authenticator adds credentials to the requests of a calendar feed.
+type authenticator interface {
+	// header returns the headers authenticating a request.
+	header(ctx context.Context, method, rawURL string) (http.Header, error)
+	// challenge records the authentication challenge of a rejected request,
+	// returning true if the request should be retried.
+	challenge(err error) bool
+}
+
+// newAuthenticator returns the authenticator of the calendar feed, or nil
+// if it is not authenticated.
+func newAuthenticator(auth Auth, f *ical.Fetcher) authenticator {
+	switch auth.Type {
+	case authBasic:
+		return staticAuth{"Basic " + base64.StdEncoding.EncodeToString([]byte(auth.Username+":"+auth.Password))}
+	case authBearer:
+		return staticAuth{"Bearer " + auth.Token}
+	case authOAuth2:
+		return &oauth2Auth{cfg: auth, f: f}
+	case authDigest:
+		return &digestAuth{username: auth.Username, password: auth.Password}
+	default:
+		return nil
+	}
+}
+
+// staticAuth sends a fixed Authorization header.
+type staticAuth struct {
+	value string
+}
+
+func (a staticAuth) header(context.Context, string, string) (http.Header, error) {
+	return http.Header{"Authorization": {a.value}}, nil
+}
+
+func (a staticAuth) challenge(error) bool { return false }
+
+// oauth2Auth sends an access token obtained with the OAuth 2 client
+// credentials grant, renewing it before it expires.
+type oauth2Auth struct {
+	cfg Auth
+	f   *ical.Fetcher
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func (a *oauth2Auth) header(ctx context.Context, _, _ string) (http.Header, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token == "" || time.Now().After(a.expires) {
+		if err := a.refresh(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return http.Header{"Authorization": {"Bearer " + a.token}}, nil
+}
+
+func (a *oauth2Auth) refresh(ctx context.Context) error {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(a.cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(a.cfg.Scopes, " "))
+	}
+	header := http.Header{
+		"Content-Type":  {"application/x-www-form-urlencoded"},
+		"Authorization": {"Basic " + base64.StdEncoding.EncodeToString([]byte(url.QueryEscape(a.cfg.ClientID)+":"+url.QueryEscape(a.cfg.ClientSecret)))},
+	}
+	b, err := a.f.Request(ctx, http.MethodPost, a.cfg.TokenURL, header, []byte(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("requesting access token: %w", err)
+	}
+
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err = json.Unmarshal(b, &resp); err != nil {
+		return fmt.Errorf("decoding access token: %w", err)
+	}
+	if resp.AccessToken == "" {
+		return errors.New("no access token returned")
+	}
+
+	a.token = resp.AccessToken
+	// Tokens are renewed a minute early to allow for clock skew, and
+	// hourly when their lifetime is not given.
+	lifetime := time.Hour
+	if resp.ExpiresIn > 0 {
+		lifetime = time.Duration(resp.ExpiresIn) * time.Second
+	}
+	a.expires = time.Now().Add(lifetime - time.Minute)
+	return nil
+}
+
+func (a *oauth2Auth) challenge(err error) bool {
+	var statusErr *ical.StatusError
+	if !errors.As(err, &statusErr) || statusErr.Code != http.StatusUnauthorized {
+		return false
+	}
+
+	// The token may have been revoked, so a new one is requested.
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	retry := a.token != ""
+	a.token = ""
+	return retry
+}
+
+// digestAuth answers HTTP digest authentication challenges.
+type digestAuth struct {
+	username string
+	password string
+
+	mu     sync.Mutex
+	params map[string]string
+	nc     int
+}
+
+func (a *digestAuth) header(_ context.Context, method, rawURL string) (http.Header, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// The first request is sent without credentials to get a challenge.
+	if a.params == nil {
+		return nil, nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing url: %w", err)
+	}
+	uri := u.RequestURI()
+
+	var h func() hash.Hash
+	algorithm := a.params["algorithm"]
+	switch strings.ToUpper(algorithm) {
+	case "", "MD5":
+		h = md5.New
+	case "SHA-256":
+		h = sha256.New
+	default:
+		return nil, fmt.Errorf("unsupported digest algorithm %q", algorithm)
+	}
+	digest := func(s string) string {
+		hh := h()
+		_, _ = hh.Write([]byte(s))
+		return hex.EncodeToString(hh.Sum(nil))
+	}
+
+	realm, nonce := a.params["realm"], a.params["nonce"]
+	ha1 := digest(a.username + ":" + realm + ":" + a.password)
+	ha2 := digest(method + ":" + uri)
+
+	fields := []string{
+		`username="` + a.username + `"`,
+		`realm="` + realm + `"`,
+		`nonce="` + nonce + `"`,
+		`uri="` + uri + `"`,
+	}
+	if qop := a.params["qop"]; qop != "" {
+		a.nc++
+		nc := fmt.Sprintf("%08x", a.nc)
+		cnonce := make([]byte, 8)
+		_, _ = rand.Read(cnonce)
+		cn := hex.EncodeToString(cnonce)
+		fields = append(fields,
+			`qop=auth`,
+			`nc=`+nc,
+			`cnonce="`+cn+`"`,
+			`response="`+digest(ha1+":"+nonce+":"+nc+":"+cn+":auth:"+ha2)+`"`,
+		)
+	} else {
+		fields = append(fields, `response="`+digest(ha1+":"+nonce+":"+ha2)+`"`)
+	}
+	if algorithm != "" {
+		fields = append(fields, "algorithm="+algorithm)
+	}
+	if opaque := a.params["opaque"]; opaque != "" {
+		fields = append(fields, `opaque="`+opaque+`"`)
+	}
+	return http.Header{"Authorization": {"Digest " + strings.Join(fields, ", ")}}, nil
+}
+
+func (a *digestAuth) challenge(err error) bool {
+	var statusErr *ical.StatusError
+	if !errors.As(err, &statusErr) || statusErr.Code != http.StatusUnauthorized {
+		return false
+	}
+	params, ok := parseDigestChallenge(statusErr.Header.Get("WWW-Authenticate"))
+	if !ok {
+		return false
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// A stale nonce is renewed, but a rejection of fresh credentials
+	// is not retried.
+	retry := a.params == nil || strings.EqualFold(params["stale"], "true")
+	a.params = params
+	a.nc = 0
+	return retry
+}
+
+// parseDigestChallenge parses the parameters of a digest challenge.
+func parseDigestChallenge(s string) (map[string]string, bool) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(s), " ")
+	if !strings.EqualFold(scheme, "Digest") {
+		return nil, false
+	}
+
+	params := map[string]string{}
+	for rest != "" {
+		var key, val string
+		key, rest, _ = strings.Cut(strings.TrimLeft(rest, " ,"), "=")
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return nil, false
+			}
+			val, rest = rest[1:end+1], rest[end+2:]
+		} else {
+			val, rest, _ = strings.Cut(rest, ",")
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(val)
+	}
+
+	// Only the auth quality of protection is supported.
+	if qop := params["qop"]; qop != "" {
+		var auth bool
+		for _, q := range strings.Split(qop, ",") {
+			auth = auth || strings.TrimSpace(q) == "auth"
+		}
+		if !auth {
+			return nil, false
+		}
+		params["qop"] = "auth"
+	}
+	return params, params["nonce"] != ""
+}
@@ -0,0 +1,209 @@
+//go:build !js
+
+package main
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // Digest authentication is defined over MD5.
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+func TestParseDigestChallenge(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   map[string]string
+		wantOK bool
+	}{
+		{
+			name:   "qop",
+			header: `Digest realm="mirror", nonce="abc", qop="auth", algorithm=MD5`,
+			want:   map[string]string{"realm": "mirror", "nonce": "abc", "qop": "auth", "algorithm": "MD5"},
+			wantOK: true,
+		},
+		{
+			name:   "qop list",
+			header: `Digest realm="mirror", nonce="abc", qop="auth-int, auth"`,
+			want:   map[string]string{"realm": "mirror", "nonce": "abc", "qop": "auth"},
+			wantOK: true,
+		},
+		{
+			name:   "unsupported qop",
+			header: `Digest realm="mirror", nonce="abc", qop="auth-int"`,
+		},
+		{
+			name:   "quoted commas",
+			header: `Digest realm="Family, Work", nonce="a,b", opaque="xyz"`,
+			want:   map[string]string{"realm": "Family, Work", "nonce": "a,b", "opaque": "xyz"},
+			wantOK: true,
+		},
+		{
+			name:   "stale",
+			header: `digest realm="mirror", nonce="def", stale=TRUE`,
+			want:   map[string]string{"realm": "mirror", "nonce": "def", "stale": "TRUE"},
+			wantOK: true,
+		},
+		{
+			name:   "no nonce",
+			header: `Digest realm="mirror"`,
+			want:   map[string]string{"realm": "mirror"},
+		},
+		{
+			name:   "unterminated quote",
+			header: `Digest realm="mirror", nonce="abc`,
+		},
+		{
+			name:   "basic",
+			header: `Basic realm="mirror"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := parseDigestChallenge(test.header)
+
+			if ok != test.wantOK {
+				t.Fatalf("got ok %t, want %t", ok, test.wantOK)
+			}
+			if test.want != nil && !maps.Equal(got, test.want) {
+				t.Errorf("got params %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDigestAuth(t *testing.T) {
+	a := &digestAuth{username: "Mufasa", password: "Circle Of Life"}
+	unauthorized := func(challenge string) error {
+		header := http.Header{}
+		header.Set("WWW-Authenticate", challenge)
+		return fmt.Errorf("fetching calendar: %w", &ical.StatusError{Code: http.StatusUnauthorized, Header: header})
+	}
+
+	header, err := a.header(context.Background(), http.MethodGet, "https://example.com/dir/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header != nil {
+		t.Errorf("got header %v before the challenge, want none", header)
+	}
+
+	if !a.challenge(unauthorized(`Digest realm="testrealm@host.com", nonce="dcd98b7102dd2f0e8b11d0f600bfb0c093", opaque="5ccc069c403ebaf9f0171e9517f40e41"`)) {
+		t.Fatal("got no retry of the first challenge")
+	}
+	header, err = a.header(context.Background(), http.MethodGet, "https://example.com/dir/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	md5Hex := func(s string) string {
+		sum := md5.Sum([]byte(s)) //nolint:gosec // Digest authentication is defined over MD5.
+		return hex.EncodeToString(sum[:])
+	}
+	ha1 := md5Hex("Mufasa:testrealm@host.com:Circle Of Life")
+	ha2 := md5Hex("GET:/dir/index.html")
+	want := `response="` + md5Hex(ha1+":dcd98b7102dd2f0e8b11d0f600bfb0c093:"+ha2) + `"`
+	if got := header.Get("Authorization"); !strings.HasPrefix(got, "Digest ") || !strings.Contains(got, want) ||
+		!strings.Contains(got, `opaque="5ccc069c403ebaf9f0171e9517f40e41"`) {
+		t.Errorf("got authorization %q, want %s", got, want)
+	}
+
+	// Rejected credentials are not retried, but a stale nonce is.
+	if a.challenge(unauthorized(`Digest realm="testrealm@host.com", nonce="n2"`)) {
+		t.Error("got a retry of rejected credentials")
+	}
+	if !a.challenge(unauthorized(`Digest realm="testrealm@host.com", nonce="n3", qop="auth", stale=true`)) {
+		t.Error("got no retry of a stale nonce")
+	}
+	header, err = a.header(context.Background(), http.MethodGet, "https://example.com/dir/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := header.Get("Authorization"); !strings.Contains(got, `nonce="n3"`) || !strings.Contains(got, "nc=00000001") {
+		t.Errorf("got authorization %q, want the renewed nonce", got)
+	}
+}
+
+func TestOAuth2Auth(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		id, secret, _ := req.BasicAuth()
+		if err := req.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		if id != "mirror" || secret != "s3cret" || req.Form.Get("grant_type") != "client_credentials" ||
+			req.Form.Get("scope") != "calendar.read" {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = fmt.Fprintf(rw, `{"access_token":"token-%d","expires_in":3600}`, requests)
+	}))
+	defer srv.Close()
+
+	a := &oauth2Auth{
+		cfg: Auth{
+			Type:         authOAuth2,
+			TokenURL:     srv.URL,
+			ClientID:     "mirror",
+			ClientSecret: "s3cret",
+			Scopes:       []string{"calendar.read"},
+		},
+		f: newFetcher(NewConfig()),
+	}
+	authorization := func() string {
+		t.Helper()
+
+		header, err := a.header(context.Background(), http.MethodGet, "https://example.com/team.ics")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return header.Get("Authorization")
+	}
+
+	tests := []struct {
+		name   string
+		before func()
+		want   string
+	}{
+		{name: "first request", want: "Bearer token-1"},
+		{name: "cached", want: "Bearer token-1"},
+		{
+			name:   "expired",
+			before: func() { a.expires = time.Now().Add(-time.Second) },
+			want:   "Bearer token-2",
+		},
+		{
+			name: "revoked",
+			before: func() {
+				if !a.challenge(fmt.Errorf("fetching calendar: %w", &ical.StatusError{Code: http.StatusUnauthorized})) {
+					t.Error("got no retry of a rejected token")
+				}
+			},
+			want: "Bearer token-3",
+		},
+	}
+	for _, test := range tests {
+		if test.before != nil {
+			test.before()
+		}
+		if got := authorization(); got != test.want {
+			t.Errorf("%s: got authorization %q, want %q", test.name, got, test.want)
+		}
+	}
+
+	if a.expires.Before(time.Now().Add(58*time.Minute)) || a.expires.After(time.Now().Add(59*time.Minute)) {
+		t.Errorf("got token expiry %s, want a minute before its lifetime", a.expires)
+	}
+	if a.challenge(fmt.Errorf("fetching calendar: %w", &ical.StatusError{Code: http.StatusForbidden})) {
+		t.Error("got a retry of a forbidden request")
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// fetchCache caches downloaded calendars by URL, so sources with the
+// same URL are downloaded once per interval.
+type fetchCache interface {
+	get(url string, maxAge time.Duration) ([]byte, bool)
+	set(url string, b []byte)
+	remove(url string)
+
+	// lock waits for a download of the URL in flight to finish, then
+	// marks the URL in flight until unlock is called. Sources loading
+	// together therefore download the calendar once, the rest finding
+	// it in the cache.
+	lock(ctx context.Context, url string) (unlock func(), err error)
+}
+
+type cacheEntry struct {
+	fetched time.Time
+	body    []byte
+}
+
+// memoryCache is an in-process fetch cache.
+type memoryCache struct {
+	mu       sync.Mutex
+	entries  map[string]cacheEntry
+	inflight map[string]chan struct{}
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{
+		entries:  map[string]cacheEntry{},
+		inflight: map[string]chan struct{}{},
+	}
+}
+
+func (c *memoryCache) get(url string, maxAge time.Duration) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[url]
+	if !ok || time.Since(e.fetched) >= maxAge {
+		return nil, false
+	}
+	return e.body, true
+}
+
+func (c *memoryCache) set(url string, b []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[url] = cacheEntry{fetched: time.Now(), body: b}
+}
+
+func (c *memoryCache) remove(url string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, url)
+}
+
+func (c *memoryCache) lock(ctx context.Context, url string) (func(), error) {
+	for {
+		c.mu.Lock()
+		done, ok := c.inflight[url]
+		if !ok {
+			done = make(chan struct{})
+			c.inflight[url] = done
+			c.mu.Unlock()
+
+			return func() {
+				c.mu.Lock()
+				delete(c.inflight, url)
+				c.mu.Unlock()
+
+				close(done)
+			}, nil
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-done:
+		}
+	}
+}
@@ -0,0 +1,93 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"context"
+	"syscall/js"
+	"time"
+)
+
+// windowCache is a fetch cache on the window, shared by all calendar
+// modules on the page.
+type windowCache struct{}
+
+// windowMap returns the Map with the given name on the window, creating
+// it if needed.
+func windowMap(name string) js.Value {
+	win := js.Global()
+	m := win.Get(name)
+	if m.IsUndefined() {
+		m = js.Global().Get("Map").New()
+		win.Set(name, m)
+	}
+	return m
+}
+
+func (windowCache) entries() js.Value {
+	return windowMap("glasslabsCalendarCache")
+}
+
+func (c windowCache) get(url string, maxAge time.Duration) ([]byte, bool) {
+	e := c.entries().Call("get", url)
+	if e.IsUndefined() {
+		return nil, false
+	}
+	fetched := time.UnixMilli(int64(e.Get("fetched").Float()))
+	if time.Since(fetched) >= maxAge {
+		return nil, false
+	}
+	return []byte(e.Get("body").String()), true
+}
+
+func (c windowCache) set(url string, b []byte) {
+	c.entries().Call("set", url, map[string]any{
+		"fetched": time.Now().UnixMilli(),
+		"body":    string(b),
+	})
+}
+
+func (c windowCache) remove(url string) {
+	c.entries().Call("delete", url)
+}
+
+// lock marks the URL in flight with a promise on the window, as each
+// module runs in its own instance and cannot share a Go channel.
+func (windowCache) lock(ctx context.Context, url string) (func(), error) {
+	fetches := windowMap("glasslabsCalendarFetches")
+	for {
+		p := fetches.Call("get", url)
+		if p.IsUndefined() {
+			break
+		}
+
+		done := make(chan struct{})
+		var onDone js.Func
+		onDone = js.FuncOf(func(js.Value, []js.Value) any {
+			close(done)
+			onDone.Release()
+			return nil
+		})
+		p.Call("then", onDone)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-done:
+		}
+	}
+
+	var resolve js.Value
+	executor := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		resolve = args[0]
+		return nil
+	})
+	p := js.Global().Get("Promise").New(executor)
+	executor.Release()
+	fetches.Call("set", url, p)
+
+	return func() {
+		fetches.Call("delete", url)
+		resolve.Invoke()
+	}, nil
+}
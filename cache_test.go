@@ -0,0 +1,93 @@
+//go:build !js
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheLock(t *testing.T) {
+	c := newMemoryCache()
+
+	unlock, err := c.lock(context.Background(), "https://example.com/family.ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err = c.lock(ctx, "https://example.com/family.ics"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v while the URL is in flight, want %v", err, context.DeadlineExceeded)
+	}
+	otherUnlock, err := c.lock(ctx, "https://example.com/work.ics")
+	if err != nil {
+		t.Fatalf("got error %v for another URL", err)
+	}
+	otherUnlock()
+
+	unlock()
+	unlock, err = c.lock(context.Background(), "https://example.com/family.ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+}
+
+func TestSourceLoadSharesFetches(t *testing.T) {
+	var requests atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		_, _ = rw.Write([]byte("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:standup\r\nSUMMARY:Standup\r\n" +
+			"DTSTAMP:20240601T000000Z\r\nDTSTART:20240603T090000Z\r\nDTEND:20240603T091500Z\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"))
+	}))
+	defer srv.Close()
+
+	cfg := NewConfig()
+	cfg.Calendars = []Calendar{{Name: "mine", URL: srv.URL}, {Name: "shared", URL: srv.URL}}
+	f := newFetcher(cfg)
+	srcs, err := newSources(cfg, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := newMemoryCache()
+	for _, src := range srcs {
+		src.cache = cache
+	}
+
+	start := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	var wg sync.WaitGroup
+	for _, src := range srcs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			cal, err := src.load(context.Background(), f, start, start.AddDate(0, 0, 1))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(cal.Events) != 1 {
+				t.Errorf("%s: got %d events, want 1", src.name(), len(cal.Events))
+			}
+		}()
+	}
+	<-started
+	close(release)
+	wg.Wait()
+
+	if got := requests.Load(); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+// calDAVSource loads the events and tasks of the calendars of a CalDAV
+// account.
+type calDAVSource struct {
+	dav        *ical.CalDAV
+	collection string
+	only       map[string]bool
+	tz         *time.Location
+
+	// mu guards the calendar objects of the loaded tasks by UID, which
+	// are updated when a task is completed.
+	mu    sync.Mutex
+	tasks map[string]ical.CalDAVObject
+}
+
+func newCalDAVSource(cal Calendar, f *ical.Fetcher, tz *time.Location) (*calDAVSource, error) {
+	if cal.Auth.Username == "" || cal.Auth.Password == "" {
+		return nil, errors.New("icloud calendar username and app-specific password are required")
+	}
+	return &calDAVSource{
+		dav:        &ical.CalDAV{Fetcher: f, URL: cal.URL, Username: cal.Auth.Username, Password: cal.Auth.Password},
+		collection: cal.Collection,
+		only:       newSubCalendars(cal.OnlyCalendars),
+		tz:         tz,
+	}, nil
+}
+
+// create adds the event to the calendar of the account, which is the
+// configured collection or else the first calendar found.
+func (s *calDAVSource) create(ctx context.Context, evnt ical.Event) error {
+	cals, err := s.dav.Calendars(ctx)
+	if err != nil {
+		return fmt.Errorf("discovering calendars: %w", err)
+	}
+	i := slices.IndexFunc(cals, func(dc ical.CalDAVCalendar) bool {
+		return dc.Events && (s.collection == "" || strings.EqualFold(dc.Name, s.collection))
+	})
+	if i < 0 {
+		if s.collection == "" {
+			return errors.New("no calendar found")
+		}
+		return fmt.Errorf("calendar %q not found", s.collection)
+	}
+
+	var buf bytes.Buffer
+	if err = ical.Encode(&buf, []ical.Event{evnt}); err != nil {
+		return fmt.Errorf("encoding event: %w", err)
+	}
+	if err = s.dav.Put(ctx, cals[i].URL, evnt.UID+".ics", buf.Bytes()); err != nil {
+		return fmt.Errorf("creating event in calendar %q: %w", cals[i].Name, err)
+	}
+	return nil
+}
+
+// complete marks the task with the given uid completed, failing if it
+// was changed on the server since it was loaded.
+func (s *calDAVSource) complete(ctx context.Context, uid string) error {
+	s.mu.Lock()
+	obj, ok := s.tasks[uid]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%q is not a caldav task", uid)
+	}
+
+	data, err := ical.CompleteTask(obj.Data, time.Now())
+	if err != nil {
+		return err
+	}
+	if err = s.dav.Update(ctx, obj.URL, obj.ETag, data); err != nil {
+		return fmt.Errorf("completing task: %w", err)
+	}
+
+	s.mu.Lock()
+	delete(s.tasks, uid)
+	s.mu.Unlock()
+	return nil
+}
+
+// events queries the events and tasks of the account's calendars within
+// the window between start and end. The calendars are discovered on each
+// load, so that moved or renamed calendars are followed.
+func (s *calDAVSource) events(ctx context.Context, start, end time.Time) ([]ical.Event, error) {
+	cals, err := s.dav.Calendars(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("discovering calendars: %w", err)
+	}
+
+	var (
+		evnts []ical.Event
+		tasks = map[string]ical.CalDAVObject{}
+		found bool
+	)
+	for _, dc := range cals {
+		if s.collection != "" && !strings.EqualFold(dc.Name, s.collection) {
+			continue
+		}
+		found = true
+		if s.only != nil && !s.only[strings.ToLower(dc.Name)] {
+			continue
+		}
+
+		if dc.Events {
+			objs, err := s.dav.Events(ctx, dc.URL, start, end)
+			if err != nil {
+				return nil, fmt.Errorf("fetching calendar %q: %w", dc.Name, err)
+			}
+			for _, obj := range objs {
+				cal, err := ical.ParseInLocation(bytes.NewReader(obj), start, end, s.tz)
+				if err != nil {
+					return nil, fmt.Errorf("parsing calendar %q: %w", dc.Name, err)
+				}
+				evnts = append(evnts, cal.Events...)
+			}
+		}
+		if dc.Tasks {
+			objs, err := s.dav.Tasks(ctx, dc.URL, start, end)
+			if err != nil {
+				return nil, fmt.Errorf("fetching tasks %q: %w", dc.Name, err)
+			}
+			for _, obj := range objs {
+				cal, err := ical.ParseInLocation(bytes.NewReader(obj.Data), start, end, s.tz)
+				if err != nil {
+					return nil, fmt.Errorf("parsing tasks %q: %w", dc.Name, err)
+				}
+				for _, evnt := range cal.Events {
+					if evnt.Kind == ical.KindTask {
+						tasks[evnt.UID] = obj
+					}
+				}
+				evnts = append(evnts, cal.Events...)
+			}
+		}
+	}
+	if !found && s.collection != "" {
+		return nil, fmt.Errorf("calendar %q not found", s.collection)
+	}
+
+	s.mu.Lock()
+	s.tasks = tasks
+	s.mu.Unlock()
+	return evnts, nil
+}
@@ -0,0 +1,174 @@
+//go:build !js
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeCalDAV is a CalDAV server with a Family calendar of one event at a
+// floating time and a Reminders list of one to-do.
+type fakeCalDAV struct {
+	t         *testing.T
+	etag      string
+	completed string
+	reports   []string
+}
+
+func (s *fakeCalDAV) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	const (
+		event = "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:dinner\r\nDTSTAMP:20240501T000000Z\r\nSUMMARY:Dinner\r\nDTSTART:20240603T180000\r\nDTEND:20240603T190000\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+		task  = "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nUID:rent\r\nSUMMARY:Pay rent\r\nDUE;VALUE=DATE:20240604\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+	)
+
+	multistatus := func(responses string) {
+		rw.WriteHeader(http.StatusMultiStatus)
+		_, _ = fmt.Fprintf(rw, `<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">%s</d:multistatus>`, responses)
+	}
+	response := func(href, props string) string {
+		return `<d:response><d:href>` + href + `</d:href><d:propstat><d:prop>` + props +
+			`</d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`
+	}
+
+	switch req.Method + " " + req.URL.Path {
+	case "PROPFIND /":
+		multistatus(response("/", `<d:current-user-principal><d:href>/principal/</d:href></d:current-user-principal>`))
+	case "PROPFIND /principal/":
+		multistatus(response("/principal/", `<c:calendar-home-set><d:href>/home/</d:href></c:calendar-home-set>`))
+	case "PROPFIND /home/":
+		multistatus(response("/home/family/", `<d:displayname>Family</d:displayname><d:resourcetype><c:calendar/></d:resourcetype>`+
+			`<c:supported-calendar-component-set><c:comp name="VEVENT"/></c:supported-calendar-component-set>`) +
+			response("/home/reminders/", `<d:displayname>Reminders</d:displayname><d:resourcetype><c:calendar/></d:resourcetype>`+
+				`<c:supported-calendar-component-set><c:comp name="VTODO"/></c:supported-calendar-component-set>`))
+	case "REPORT /home/family/":
+		s.reports = append(s.reports, req.URL.Path)
+		if !strings.Contains(readBody(s.t, req), `name="VEVENT"`) {
+			s.t.Error("queried the event calendar for other components")
+		}
+		multistatus(response("/home/family/dinner.ics", `<d:getetag>"1"</d:getetag><c:calendar-data>`+event+`</c:calendar-data>`))
+	case "REPORT /home/reminders/":
+		s.reports = append(s.reports, req.URL.Path)
+		if !strings.Contains(readBody(s.t, req), `name="VTODO"`) {
+			s.t.Error("queried the task calendar for other components")
+		}
+		multistatus(response("/home/reminders/rent.ics", `<d:getetag>`+s.etag+`</d:getetag><c:calendar-data>`+task+`</c:calendar-data>`))
+	case "PUT /home/reminders/rent.ics":
+		if req.Header.Get("If-Match") != s.etag {
+			rw.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		s.completed = readBody(s.t, req)
+		rw.WriteHeader(http.StatusNoContent)
+	default:
+		s.t.Errorf("unexpected request %s %s", req.Method, req.URL.Path)
+		rw.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestCalDAVSourceCompleteTask(t *testing.T) {
+	dav := &fakeCalDAV{t: t, etag: `"1"`}
+	srv := httptest.NewServer(dav)
+	defer srv.Close()
+
+	cfg := NewConfig()
+	f := newFetcher(cfg)
+	src, err := newCalDAVSource(Calendar{URL: srv.URL, Auth: Auth{Type: authBasic, Username: "me", Password: "secret"}, Collection: "Reminders"}, f, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	evnts, err := src.events(context.Background(), start, start.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evnts) != 1 || evnts[0].UID != "rent" || evnts[0].Kind != kindTask {
+		t.Fatalf("got events %+v, want the rent task", evnts)
+	}
+
+	// A task changed on the server since it was loaded is not completed.
+	dav.etag = `"2"`
+	if err = src.complete(context.Background(), "rent"); err == nil {
+		t.Error("completed a task changed on the server")
+	}
+	dav.etag = `"1"`
+
+	if err = src.complete(context.Background(), "rent"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(dav.completed, "STATUS:COMPLETED\r\n") || !strings.Contains(dav.completed, "UID:rent\r\n") {
+		t.Errorf("got completed task:\n%s", dav.completed)
+	}
+	if err = src.complete(context.Background(), "rent"); err == nil {
+		t.Error("completed the task twice")
+	}
+}
+
+func TestCalDAVSourceOnlyCalendars(t *testing.T) {
+	dav := &fakeCalDAV{t: t, etag: `"1"`}
+	srv := httptest.NewServer(dav)
+	defer srv.Close()
+
+	cfg := NewConfig()
+	f := newFetcher(cfg)
+	src, err := newCalDAVSource(Calendar{URL: srv.URL, Auth: Auth{Type: authBasic, Username: "me", Password: "secret"}, OnlyCalendars: []string{"family"}}, f, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	evnts, err := src.events(context.Background(), start, start.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := eventIDs(evnts); !slices.Equal(got, []string{"dinner/20240603T180000Z"}) {
+		t.Errorf("got events %q, want only those of the family calendar", got)
+	}
+	if !slices.Equal(dav.reports, []string{"/home/family/"}) {
+		t.Errorf("got queries of %q, want only the family calendar", dav.reports)
+	}
+}
+
+func TestCalDAVSourceFloatingTimes(t *testing.T) {
+	dav := &fakeCalDAV{t: t, etag: `"1"`}
+	srv := httptest.NewServer(dav)
+	defer srv.Close()
+
+	tz, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := NewConfig()
+	f := newFetcher(cfg)
+	src, err := newCalDAVSource(Calendar{URL: srv.URL, Auth: Auth{Type: authBasic, Username: "me", Password: "secret"}, OnlyCalendars: []string{"family"}}, f, tz)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	evnts, err := src.events(context.Background(), start, start.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 6, 3, 18, 0, 0, 0, tz); len(evnts) != 1 || !evnts[0].Start.Equal(want) {
+		t.Errorf("got events %+v, want dinner at %s", evnts, want)
+	}
+}
+
+func readBody(t *testing.T, req *http.Request) string {
+	t.Helper()
+
+	b, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Error(err)
+	}
+	return string(b)
+}
@@ -0,0 +1,767 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+	"github.com/glasslabs/calendar/pkg/render"
+)
+
+// Source health states.
+const (
+	healthOK    = "ok"
+	healthStale = "stale"
+	healthError = "error"
+)
+
+// Calendar types.
+const (
+	calendarICloud  = "icloud"
+	calendarGitHub  = "github"
+	calendarJira    = "jira"
+	calendarTodoist = "todoist"
+	calendarTrello  = "trello"
+)
+
+// defaultURLs are the default URLs of the calendar types with a
+// well known server.
+var defaultURLs = map[string]string{
+	calendarICloud:  "https://caldav.icloud.com",
+	calendarGitHub:  "https://api.github.com",
+	calendarTodoist: "https://api.todoist.com",
+	calendarTrello:  "https://api.trello.com",
+}
+
+// eventSource loads events from an API rather than a calendar feed.
+type eventSource interface {
+	events(ctx context.Context, start, end time.Time) ([]ical.Event, error)
+}
+
+// taskCompleter is an event source whose tasks can be marked done.
+type taskCompleter interface {
+	complete(ctx context.Context, uid string) error
+}
+
+// source is the runtime state of a configured calendar.
+type source struct {
+	cal      Calendar
+	timeout  time.Duration
+	interval time.Duration
+	cache    fetchCache
+	api      eventSource
+	auth     authenticator
+	tz       *time.Location
+	file     string
+	only     map[string]bool
+
+	// mu guards the expansion, as a refresh may load the source
+	// while it is loaded on its interval.
+	mu        sync.Mutex
+	expansion *expansion
+
+	events    []ical.Event
+	title     string
+	cancelled map[string]time.Time
+	fetched   time.Time
+	err       error
+	failures  int
+}
+
+// name returns the display name of the source, preferring the name
+// declared by its feed over the default name.
+func (s *source) name() string {
+	if s.cal.Name == "" && s.title != "" {
+		return s.title
+	}
+	return calendarName(s.cal)
+}
+
+// calendarName returns the display name of the calendar, defaulting
+// to its CalDAV collection or the host of its URL.
+func calendarName(cal Calendar) string {
+	if cal.Name != "" {
+		return cal.Name
+	}
+	if cal.Collection != "" {
+		return cal.Collection
+	}
+	if u, err := url.Parse(cmp.Or(cal.URL, defaultURLs[cal.Type])); err == nil {
+		if file, ok := fixturePath(u); ok {
+			return path.Base(file)
+		}
+		return u.Host
+	}
+	return cal.URL
+}
+
+// removeDisabled removes the calendars that are turned off from the
+// configuration, so they are neither validated nor loaded.
+func (c *Config) removeDisabled() {
+	c.Calendars = slices.DeleteFunc(c.Calendars, func(cal Calendar) bool {
+		return cal.Enabled != nil && !*cal.Enabled
+	})
+}
+
+// status returns the health of the source. A source is stale when
+// it has not been fetched successfully for two intervals.
+func (s *source) status(now time.Time) string {
+	switch {
+	case s.err != nil:
+		return healthError
+	case now.Sub(s.fetched) > 2*s.interval:
+		return healthStale
+	default:
+		return healthOK
+	}
+}
+
+// newSources returns the sources for the configured calendars.
+func newSources(cfg Config, f *ical.Fetcher) ([]*source, error) {
+	tz, err := loadTimezone(cfg.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	srcs := make([]*source, 0, len(cfg.Calendars))
+	for _, cal := range cfg.Calendars {
+		cal.URL = cmp.Or(cal.URL, defaultURLs[cal.Type])
+
+		var api eventSource
+		switch cal.Type {
+		case "":
+		case calendarICloud:
+			api, err = newCalDAVSource(cal, f, tz)
+		case calendarGitHub:
+			api, err = newGitHubSource(cal, f, tz)
+		case calendarJira:
+			api, err = newJiraSource(cal, f, tz)
+		case calendarTodoist:
+			api, err = newTodoistSource(cal, f, tz)
+		case calendarTrello:
+			api, err = newTrelloSource(cal, f)
+		default:
+			err = fmt.Errorf("unknown calendar type %q", cal.Type)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		u, err := url.Parse(cal.URL)
+		if err != nil {
+			return nil, fmt.Errorf("parsing calendar url %q: %w", cal.URL, err)
+		}
+		file, isFile := fixturePath(u)
+		if isFile {
+			err = validateFixtureURL(cfg.Fixture, cal, u)
+		} else {
+			err = f.ValidateURL(u)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("validating calendar url %q: %w", cal.URL, err)
+		}
+
+		var calTZ *time.Location
+		if cal.Timezone != "" {
+			if calTZ, err = loadTimezone(cal.Timezone); err != nil {
+				return nil, err
+			}
+		}
+
+		timeout := cfg.Timeout
+		if cal.Timeout > 0 {
+			timeout = cal.Timeout
+		}
+		interval := cfg.Interval
+		if cal.Interval > 0 {
+			interval = cal.Interval
+		}
+		srcs = append(srcs, &source{
+			cal:      cal,
+			timeout:  timeout,
+			interval: interval,
+			api:      api,
+			auth:     newAuthenticator(cal.Auth, f),
+			tz:       calTZ,
+			file:     file,
+			only:     newSubCalendars(cal.OnlyCalendars),
+		})
+	}
+	if cfg.Demo {
+		srcs = append(srcs, &source{
+			cal:      demoCalendar,
+			timeout:  cfg.Timeout,
+			interval: cfg.Interval,
+			api:      demoSource{tz: tz},
+		})
+	}
+	return srcs, nil
+}
+
+// loadTimezone returns the named timezone, defaulting to the local timezone.
+func loadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		//nolint:gosmopolitan
+		return time.Local, nil
+	}
+
+	tz, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("parsing timezone: %w", err)
+	}
+	return tz, nil
+}
+
+// newFetcher returns the fetcher shared by all calendar fetches.
+func newFetcher(cfg Config) *ical.Fetcher {
+	f := ical.NewFetcher()
+	f.UserAgent = cfg.UserAgent
+	f.MaxRedirects = cfg.MaxRedirects
+	f.AllowedHosts = cfg.AllowedHosts
+	f.MaxBodySize = cfg.MaxFeedSize
+	f.Limiter = ical.NewHostLimiter(cfg.RateLimit)
+
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.MaxIdleConns = cfg.MaxIdleConns
+	tr.MaxIdleConnsPerHost = cfg.MaxIdleConns
+	tr.IdleConnTimeout = cfg.IdleConnTimeout
+	tr.TLSClientConfig = &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ClientSessionCache: tls.NewLRUClientSessionCache(0),
+	}
+	f.Client.Transport = tr
+
+	return f
+}
+
+// cached returns the cached calendar of the source, if downloaded
+// within its interval.
+func (s *source) cached() ([]byte, bool) {
+	if s.cache == nil {
+		return nil, false
+	}
+	return s.cache.get(s.cal.URL, s.interval)
+}
+
+// fetch downloads the calendar feed of the source unless it has not
+// changed since it was fetched with the validators v, answering an
+// authentication challenge once.
+func (s *source) fetch(ctx context.Context, f *ical.Fetcher, v ical.Validators) ([]byte, ical.Validators, error) {
+	if s.file != "" {
+		b, err := os.ReadFile(s.file) //nolint:gosec // The path is provided by the user.
+		return b, ical.Validators{}, err
+	}
+	if s.auth == nil {
+		return f.FetchIfModified(ctx, s.cal.URL, nil, v)
+	}
+
+	for attempt := 0; ; attempt++ {
+		header, err := s.auth.header(ctx, http.MethodGet, s.cal.URL)
+		if err != nil {
+			return nil, ical.Validators{}, fmt.Errorf("authenticating: %w", err)
+		}
+		b, newV, err := f.FetchIfModified(ctx, s.cal.URL, header, v)
+		if err == nil || attempt > 0 || !s.auth.challenge(err) {
+			return b, newV, err
+		}
+	}
+}
+
+// load fetches the calendar of the source within the window between
+// start and end.
+func (s *source) load(ctx context.Context, f *ical.Fetcher, start, end time.Time) (*ical.Calendar, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if s.api != nil {
+		evnts, err := s.api.events(ctx, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("loading calendar %q: %w", s.name(), err)
+		}
+		ical.Sort(evnts)
+		for i := range evnts {
+			evnts[i].Calendar = calendarName(s.cal)
+		}
+		return &ical.Calendar{Events: ical.Limit(evnts, s.cal.MaxEvents)}, nil
+	}
+
+	if s.cache != nil {
+		unlock, err := s.cache.lock(ctx, s.cal.URL)
+		if err != nil {
+			return nil, fmt.Errorf("waiting for calendar %q: %w", redactURL(s.cal.URL), err)
+		}
+		defer unlock()
+	}
+
+	b, cached := s.cached()
+	var v ical.Validators
+	if !cached {
+		var err error
+		b, v, err = s.fetch(ctx, f, s.validators(start, end))
+		switch {
+		case errors.Is(err, ical.ErrNotModified):
+			// The expansion of the unchanged feed is reused.
+			b = nil
+		case err != nil:
+			return nil, fmt.Errorf("fetching calendar %q: %w", redactURL(s.cal.URL), err)
+		}
+	}
+
+	cal, err := s.parse(b, v, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("parsing calendar %q: %w", redactURL(s.cal.URL), err)
+	}
+	if !cached && b != nil && s.cache != nil {
+		s.cache.set(s.cal.URL, b)
+	}
+	for i := range cal.Events {
+		cal.Events[i].Calendar = calendarName(s.cal)
+	}
+	cal.Events = ical.Limit(cal.Events, s.cal.MaxEvents)
+	return cal, nil
+}
+
+// generator generates events locally rather than fetching them.
+type generator interface {
+	generate(start, end time.Time) []ical.Event
+}
+
+// pipeline turns fetched events into events for display.
+type pipeline struct {
+	cfg        Config
+	tz         *time.Location
+	altTZ      *time.Location
+	transform  ical.EventTransformer
+	generators []generator
+
+	anniversaries []anniversary
+	secondary     secondaryCalendar
+	weekStart     time.Weekday
+	hours         *workingHours
+	quiet         *quietHours
+	icons         []iconRule
+	colors        []colorRule
+	calendarIcons map[string]string
+	presets       map[string]string
+	chimes        map[string]time.Duration
+	completable   map[string]bool
+	profiles      []*profile
+}
+
+func newPipeline(cfg Config) (*pipeline, error) {
+	tz, err := loadTimezone(cfg.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	var altTZ *time.Location
+	if cfg.SecondaryTimezone != "" {
+		if altTZ, err = loadTimezone(cfg.SecondaryTimezone); err != nil {
+			return nil, err
+		}
+	}
+	transform, err := newTransformers(cfg.Transforms)
+	if err != nil {
+		return nil, err
+	}
+
+	var gens []generator
+	if cfg.Astro.Sunrise || cfg.Astro.Sunset || cfg.Astro.MoonPhases {
+		gens = append(gens, astro{cfg: cfg.Astro, tz: tz})
+	}
+	if len(cfg.StaticEvents) > 0 {
+		static, err := newStaticEvents(cfg.StaticEvents, tz)
+		if err != nil {
+			return nil, err
+		}
+		gens = append(gens, static)
+	}
+	for _, ttCfg := range cfg.Timetables {
+		tt, err := newTimetable(ttCfg, tz)
+		if err != nil {
+			return nil, err
+		}
+		gens = append(gens, tt)
+	}
+
+	annivs, err := newAnniversaries(cfg.Anniversaries, tz)
+	if err != nil {
+		return nil, err
+	}
+	secondary, err := newSecondaryCalendar(cfg.SecondaryCalendar)
+	if err != nil {
+		return nil, err
+	}
+	weekStart, err := parseWeekStart(cfg.WeekStart)
+	if err != nil {
+		return nil, err
+	}
+	if err = validateUnits(cfg.Home.Units); err != nil {
+		return nil, err
+	}
+	hours, err := newWorkingHours(cfg.WorkingHours)
+	if err != nil {
+		return nil, err
+	}
+	quiet, err := newQuietHours(cfg.QuietHours)
+	if err != nil {
+		return nil, err
+	}
+	icons, err := newIconRules(cfg.Icons)
+	if err != nil {
+		return nil, err
+	}
+	colors, err := newColorRules(cfg.Colors)
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := newProfiles(cfg.Profiles)
+	if err != nil {
+		return nil, err
+	}
+	calIcons := map[string]string{}
+	presets := map[string]string{}
+	chimes := map[string]time.Duration{}
+	completable := map[string]bool{}
+	for _, cal := range cfg.Calendars {
+		switch cal.Preset {
+		case "":
+		case presetMedia:
+			if cal.Icon == "" {
+				cal.Icon = mediaIcons[cfg.IconFont]
+			}
+		case presetCanvas:
+		default:
+			return nil, fmt.Errorf("unknown calendar preset %q", cal.Preset)
+		}
+		if cal.Preset != "" {
+			presets[calendarName(cal)] = cal.Preset
+		}
+		if cal.Icon != "" {
+			calIcons[calendarName(cal)] = cal.Icon
+		}
+		if lead := cmp.Or(cal.ChimeBefore, cfg.Chime.LeadTime); lead > 0 {
+			chimes[calendarName(cal)] = lead
+		}
+		if cal.CompleteTasks {
+			completable[calendarName(cal)] = true
+		}
+	}
+
+	return &pipeline{
+		cfg:           cfg,
+		tz:            tz,
+		altTZ:         altTZ,
+		transform:     transform,
+		generators:    gens,
+		anniversaries: annivs,
+		secondary:     secondary,
+		weekStart:     weekStart,
+		hours:         hours,
+		quiet:         quiet,
+		icons:         icons,
+		colors:        colors,
+		calendarIcons: calIcons,
+		presets:       presets,
+		chimes:        chimes,
+		completable:   completable,
+		profiles:      profiles,
+	}, nil
+}
+
+// generate returns the generated events between start and end.
+func (p *pipeline) generate(start, end time.Time) []ical.Event {
+	var evnts []ical.Event
+	for _, g := range p.generators {
+		evnts = append(evnts, g.generate(start, end)...)
+	}
+	return evnts
+}
+
+// mergeEvents merges, transforms and limits the given events,
+// converting them for display. It returns the number of events
+// dropped by the limit.
+func (p *pipeline) mergeEvents(evnts []ical.Event) ([]render.Event, int) {
+	evnts, more := p.selectEvents(evnts)
+	return p.toEvents(evnts), more
+}
+
+// windowEnd returns the end of the window of days to show events for
+// starting at start. Weekends are not counted when showing only
+// business days.
+func (p *pipeline) windowEnd(start time.Time) time.Time {
+	if !p.cfg.BusinessDays {
+		return start.Add(time.Duration(p.cfg.MaxDays) * 24 * time.Hour)
+	}
+
+	end := start.In(p.tz)
+	for days := 0; days < p.cfg.MaxDays; {
+		end = end.AddDate(0, 0, 1)
+		if !isWeekend(end) {
+			days++
+		}
+	}
+	return end
+}
+
+// selectEvents sorts, transforms and limits the given events, returning
+// the number of events dropped by the limit.
+func (p *pipeline) selectEvents(evnts []ical.Event) ([]ical.Event, int) {
+	ical.Sort(evnts)
+	evnts = p.transform.Transform(evnts)
+	if p.cfg.BusinessDays {
+		evnts = p.excludeWeekends(evnts)
+	}
+	if p.cfg.CollapseRecurring {
+		evnts = ical.CollapseRecurring().Transform(evnts)
+	}
+	limited := ical.Limit(evnts, p.cfg.MaxEvents)
+	return limited, len(evnts) - len(limited)
+}
+
+// excludeWeekends removes events starting on a weekend.
+func (p *pipeline) excludeWeekends(evnts []ical.Event) []ical.Event {
+	res := evnts[:0]
+	for _, evnt := range evnts {
+		start := evnt.Start
+		if !evnt.AllDay {
+			start = start.In(p.tz)
+		}
+		if !isWeekend(start) {
+			res = append(res, evnt)
+		}
+	}
+	return res
+}
+
+// withAnniversaries returns the events with the anniversaries as of now,
+// sorted by time. Anniversaries are computed on each call so their
+// counts stay current.
+func (p *pipeline) withAnniversaries(events []render.Event, now time.Time) []render.Event {
+	if len(p.anniversaries) == 0 {
+		return events
+	}
+
+	events = append(events, anniversaryEvents(p.anniversaries, now.In(p.tz), p.cfg.MaxDays)...)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+	return events
+}
+
+// displayTime returns t in the display timezone. All-day events are
+// parsed at midnight UTC, so they keep their date rather than moving to
+// the previous day west of UTC.
+func (p *pipeline) displayTime(t time.Time, allDay bool) time.Time {
+	if !allDay {
+		return t.In(p.tz)
+	}
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, p.tz)
+}
+
+// altDate returns the date of t in the secondary calendar, if configured.
+func (p *pipeline) altDate(t time.Time) string {
+	if p.secondary == nil {
+		return ""
+	}
+	y, m, d := t.Date()
+	return p.secondary(y, m, d)
+}
+
+// quietMode returns the quiet hours mode at t, or an empty string
+// outside of quiet hours.
+func (p *pipeline) quietMode(t time.Time) string {
+	if p.quiet == nil || !p.quiet.contains(t.In(p.tz)) {
+		return ""
+	}
+	return p.quiet.mode
+}
+
+// toEvents converts events for display in the timezone.
+func (p *pipeline) toEvents(evnts []ical.Event) []render.Event {
+	events := make([]render.Event, 0, len(evnts))
+	for _, evnt := range evnts {
+		color, class := p.color(evnt)
+		event := render.Event{
+			ID:          eventID(evnt),
+			Icon:        p.icon(evnt),
+			Color:       color,
+			Class:       class,
+			Kind:        evnt.Kind,
+			Title:       evnt.Summary,
+			Location:    evnt.Location,
+			Distance:    p.distance(evnt),
+			Description: evnt.Description,
+			Attendees:   evnt.Attendees,
+			Recurrence:  ical.DescribeRule(evnt.RecurrenceRule),
+			Time:        p.displayTime(evnt.Start, evnt.AllDay),
+			End:         p.displayTime(evnt.End, evnt.AllDay),
+			IsAllDay:    evnt.AllDay,
+			IsSeries:    p.cfg.CollapseRecurring && evnt.IsRecurring,
+			IsOffHours:  p.hours != nil && !evnt.AllDay && !p.hours.overlaps(evnt.Start.In(p.tz), evnt.End.In(p.tz)),
+			CanComplete: evnt.Kind == kindTask && p.completable[evnt.Calendar],
+			Alarms:      alarmTimes(evnt),
+			Props:       evnt.Props,
+		}
+		if p.altTZ != nil && !evnt.AllDay {
+			event.AltTime = evnt.Start.In(p.altTZ).Format("15:04 MST")
+		}
+		if lead, ok := p.chimes[evnt.Calendar]; ok && !evnt.AllDay {
+			event.Chime = evnt.Start.Add(-lead)
+		}
+		switch p.presets[evnt.Calendar] {
+		case presetMedia:
+			applyEpisode(&event, evnt)
+		case presetCanvas:
+			applyAssignment(&event, evnt)
+		}
+		events = append(events, event)
+	}
+	markConflicts(evnts, events)
+
+	// All-day events keep their date rather than their instant, so they
+	// are sorted again to keep the events in order for the day index.
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+	return events
+}
+
+// eventIDLayout is the layout of the start time in event ids.
+const eventIDLayout = "20060102T150405Z"
+
+// eventID returns an id for the event unique to each instance of
+// recurring events.
+func eventID(evnt ical.Event) string {
+	return ical.InstanceID(evnt.UID, evnt.Start)
+}
+
+// eventIDStart returns the start time of the event with the given id.
+func eventIDStart(id string) (time.Time, bool) {
+	i := strings.LastIndexByte(id, '/')
+	if i < 0 {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(eventIDLayout, id[i+1:])
+	return t, err == nil
+}
+
+// markConflicts flags timed events that overlap another timed event.
+// The events must be sorted by start time.
+func markConflicts(evnts []ical.Event, events []render.Event) {
+	last := -1
+	var lastEnd time.Time
+	for i, evnt := range evnts {
+		if evnt.AllDay {
+			continue
+		}
+		if last >= 0 && evnt.Start.Before(lastEnd) {
+			events[i].HasConflict = true
+			events[last].HasConflict = true
+		}
+		if last < 0 || evnt.End.After(lastEnd) {
+			last, lastEnd = i, evnt.End
+		}
+	}
+}
+
+// errorClass returns a short description of the kind of fetch error.
+func errorClass(err error) string {
+	var (
+		statusErr *ical.StatusError
+		urlErr    *url.Error
+	)
+	switch {
+	case errors.As(err, &statusErr):
+		return "HTTP " + strconv.Itoa(statusErr.Code)
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &urlErr) && urlErr.Timeout():
+		return "timeout"
+	case urlErr != nil:
+		return "network error"
+	default:
+		return "invalid feed"
+	}
+}
+
+// parseWeekStart parses the first day of the week, which may be
+// monday, sunday or saturday.
+func parseWeekStart(s string) (time.Weekday, error) {
+	day, err := parseWeekday(s)
+	if err != nil || (day != time.Monday && day != time.Sunday && day != time.Saturday) {
+		return 0, fmt.Errorf("invalid week start %q", s)
+	}
+	return day, nil
+}
+
+// startOfWeek returns the first day of the week containing t.
+func startOfWeek(t time.Time, weekStart time.Weekday) time.Time {
+	y, m, d := t.Date()
+	offset := (int(t.Weekday()) - int(weekStart) + 7) % 7
+	return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+}
+
+// isWeekend reports whether t falls on a Saturday or Sunday.
+func isWeekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
+
+// hasUpcoming reports whether any event is in progress or starts
+// within the given time of now.
+func hasUpcoming(events []render.Event, now time.Time, within time.Duration) bool {
+	horizon := now.Add(within)
+	for _, evnt := range events {
+		if evnt.Time.After(horizon) {
+			continue
+		}
+		if !evnt.Time.Before(now) || evnt.End.After(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// Urgency buckets of the time until an event starts.
+const (
+	urgencyLater    = "later"
+	urgencyDay      = "day"
+	urgencyHour     = "hour"
+	urgencyImminent = "imminent"
+)
+
+// urgency returns the urgency bucket of an event starting at t, or an
+// empty string for all day events and events that have started.
+func urgency(t time.Time, allDay bool, now time.Time) string {
+	until := t.Sub(now)
+	switch {
+	case allDay || until <= 0:
+		return ""
+	case until < 15*time.Minute:
+		return urgencyImminent
+	case until < time.Hour:
+		return urgencyHour
+	case until < 24*time.Hour:
+		return urgencyDay
+	default:
+		return urgencyLater
+	}
+}
+
+// isToday reports whether t falls on the same day as now in the location of t.
+func isToday(t, now time.Time) bool {
+	y1, m1, d1 := t.Date()
+	y2, m2, d2 := now.In(t.Location()).Date()
+	return y1 == y2 && m1 == m2 && d1 == d2
+}
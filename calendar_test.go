@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+func BenchmarkMergeEvents(b *testing.B) {
+	cfg := NewConfig()
+	cfg.Timezone = "Europe/London"
+	cfg.Fixture.Now = "2024-06-03T08:00:00+01:00"
+	cfg.MaxDays = 7
+	cfg.MaxEvents = 50
+	pipe, err := newPipeline(cfg)
+	if err != nil {
+		b.Fatal(err)
+	}
+	clock, err := newClock(cfg.Fixture)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	// Four busy calendars of 500 events each within the week.
+	start := clock.Now()
+	var sources []*source
+	for i := range 4 {
+		src := &source{cal: Calendar{Name: fmt.Sprintf("Calendar %d", i)}}
+		for j := range 500 {
+			at := start.Add(time.Duration(j*4+i) * 5 * time.Minute)
+			src.events = append(src.events, ical.Event{
+				UID:      fmt.Sprintf("event-%d-%d@example.com", i, j),
+				Summary:  fmt.Sprintf("Meeting %d", j),
+				Location: "Room 1",
+				Start:    at,
+				End:      at.Add(30 * time.Minute),
+				Calendar: src.cal.Name,
+			})
+		}
+		sources = append(sources, src)
+	}
+
+	// The merge buffer is reused between refreshes, as the module does.
+	var merged []ical.Event
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		evnts := merged[:0]
+		for _, src := range sources {
+			evnts = append(evnts, src.events...)
+		}
+		events, _ := pipe.mergeEvents(evnts)
+		_ = newDayIndex(events)
+		merged = evnts[:0]
+	}
+}
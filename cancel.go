@@ -0,0 +1,51 @@
+package main
+
+import (
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+// cancel records the cancelled events of a fetch of the source, so they
+// stay suppressed on later fetches that no longer publish the cancellation.
+// Cancelled instances are kept until a day after they start, and cancelled
+// events until the end of the fetched window.
+func (s *source) cancel(ids []string, fetched, end time.Time) {
+	for id, until := range s.cancelled {
+		if !until.After(fetched) {
+			delete(s.cancelled, id)
+		}
+	}
+
+	for _, id := range ids {
+		until := end
+		if start, ok := eventIDStart(id); ok {
+			until = start.Add(24 * time.Hour)
+		}
+		if s.cancelled == nil {
+			s.cancelled = map[string]time.Time{}
+		}
+		if until.After(s.cancelled[id]) {
+			s.cancelled[id] = until
+		}
+	}
+}
+
+// suppress removes the cancelled events from the events.
+func (s *source) suppress(evnts []ical.Event) []ical.Event {
+	if len(s.cancelled) == 0 {
+		return evnts
+	}
+
+	res := make([]ical.Event, 0, len(evnts))
+	for _, evnt := range evnts {
+		if _, ok := s.cancelled[evnt.UID]; ok {
+			continue
+		}
+		if _, ok := s.cancelled[eventID(evnt)]; ok {
+			continue
+		}
+		res = append(res, evnt)
+	}
+	return res
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+func TestSourceCancel(t *testing.T) {
+	fetched := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	end := fetched.AddDate(0, 0, 5)
+	series := ical.Event{UID: "series@example.com", Start: time.Date(2024, 6, 4, 9, 0, 0, 0, time.UTC)}
+	next := ical.Event{UID: "series@example.com", Start: time.Date(2024, 6, 5, 9, 0, 0, 0, time.UTC)}
+	single := ical.Event{UID: "single@example.com", Start: time.Date(2024, 6, 6, 9, 0, 0, 0, time.UTC)}
+	other := ical.Event{UID: "other@example.com", Start: time.Date(2024, 6, 6, 12, 0, 0, 0, time.UTC)}
+	evnts := []ical.Event{series, next, single, other}
+
+	tests := []struct {
+		name    string
+		fetched time.Time
+		want    []ical.Event
+	}{
+		{
+			name:    "suppressed on the next fetch",
+			fetched: fetched.Add(time.Hour),
+			want:    []ical.Event{next, other},
+		},
+		{
+			name:    "instance expires a day after it starts",
+			fetched: series.Start.Add(24 * time.Hour),
+			want:    []ical.Event{series, next, other},
+		},
+		{
+			name:    "event expires at the end of the window",
+			fetched: end,
+			want:    evnts,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			src := &source{}
+			src.cancel([]string{eventID(series), single.UID}, fetched, end)
+			if got := src.suppress(evnts); !slices.EqualFunc(got, []ical.Event{next, other}, sameEvent) {
+				t.Fatalf("got %v after cancelling", eventIDs(got))
+			}
+
+			// A later fetch no longer publishes the cancellations.
+			src.cancel(nil, test.fetched, test.fetched.AddDate(0, 0, 5))
+
+			if got := src.suppress(evnts); !slices.EqualFunc(got, test.want, sameEvent) {
+				t.Errorf("got %v, want %v", eventIDs(got), eventIDs(test.want))
+			}
+		})
+	}
+}
+
+func sameEvent(a, b ical.Event) bool {
+	return eventID(a) == eventID(b)
+}
+
+func eventIDs(evnts []ical.Event) []string {
+	ids := make([]string, 0, len(evnts))
+	for _, evnt := range evnts {
+		ids = append(ids, eventID(evnt))
+	}
+	return ids
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+	"github.com/glasslabs/calendar/pkg/render"
+)
+
+// presetCanvas is the preset of Canvas LMS calendar feeds.
+const presetCanvas = "canvas"
+
+// kindAssignment is the kind of events that are assignments.
+const kindAssignment = "assignment"
+
+// courseRE matches the course Canvas appends to event titles, as in
+// "Essay draft [ENG 101]".
+var courseRE = regexp.MustCompile(`^(.+?)\s*\[([^\[\]]+)\]$`)
+
+// applyAssignment moves the course of a Canvas event from its title,
+// marking assignments as such. Canvas assignment UIDs are of the form
+// "event-assignment-123".
+func applyAssignment(event *render.Event, evnt ical.Event) {
+	if m := courseRE.FindStringSubmatch(evnt.Summary); m != nil {
+		event.Title = m[1]
+		event.Course = m[2]
+	}
+	if strings.Contains(evnt.UID, "assignment") {
+		event.Kind = kindAssignment
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"slices"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/render"
+)
+
+// chimesEnabled reports whether chimes are dispatched before the events
+// of any calendar.
+func (c Config) chimesEnabled() bool {
+	return c.Chime.LeadTime > 0 || slices.ContainsFunc(c.Calendars, func(cal Calendar) bool { return cal.ChimeBefore > 0 })
+}
+
+// chimes tracks the chimes played before events.
+type chimes struct {
+	fired map[string]time.Time
+}
+
+func newChimes() *chimes {
+	return &chimes{fired: map[string]time.Time{}}
+}
+
+// due returns the events whose chime is due and that have not started,
+// chiming at most once per event.
+func (c *chimes) due(events []render.Event, now time.Time) []render.Event {
+	for id, start := range c.fired {
+		if !start.After(now) {
+			delete(c.fired, id)
+		}
+	}
+
+	var due []render.Event
+	for _, evnt := range events {
+		if evnt.Chime.IsZero() || evnt.Chime.After(now) || !evnt.Time.After(now) {
+			continue
+		}
+		if _, ok := c.fired[evnt.ID]; ok {
+			continue
+		}
+		c.fired[evnt.ID] = evnt.Time
+		due = append(due, evnt)
+	}
+	return due
+}
+
+// next returns the time of the next chime after now, or the zero time
+// if there is none.
+func (c *chimes) next(events []render.Event, now time.Time) time.Time {
+	var next time.Time
+	for _, evnt := range events {
+		if evnt.Chime.After(now) && (next.IsZero() || evnt.Chime.Before(next)) {
+			next = evnt.Chime
+		}
+	}
+	return next
+}
@@ -0,0 +1,26 @@
+package main
+
+import "time"
+
+// Clock provides the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock is a clock using the system time.
+type systemClock struct{}
+
+// Now returns the current system time.
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// fixedClock is a clock pinned to a time.
+type fixedClock struct {
+	now time.Time
+}
+
+// Now returns the pinned time.
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
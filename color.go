@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+// colorRule colors events with a title matching its pattern.
+type colorRule struct {
+	re    *regexp.Regexp
+	color string
+	class string
+}
+
+func newColorRules(cfgs []Color) ([]colorRule, error) {
+	rules := make([]colorRule, 0, len(cfgs))
+	for i, cfg := range cfgs {
+		re, err := regexp.Compile(cfg.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("color %d: parsing pattern: %w", i, err)
+		}
+		class := cfg.Class
+		if class == "" {
+			class = "keyword-" + strconv.Itoa(i)
+		}
+		rules = append(rules, colorRule{re: re, color: cfg.Color, class: class})
+	}
+	return rules, nil
+}
+
+// color returns the color and style class of the event from the first
+// matching rule.
+func (p *pipeline) color(evnt ical.Event) (string, string) {
+	for _, rule := range p.colors {
+		if rule.re.MatchString(evnt.Summary) {
+			return rule.color, rule.class
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,85 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+// completeMessage is received on the "calendar.complete" topic.
+type completeMessage struct {
+	Module string `json:"module"`
+	ID     string `json:"id"`
+}
+
+// handleComplete marks a task done.
+func (m *Module) handleComplete(ctx context.Context, data []byte) {
+	var msg completeMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		m.log.Error("Could not parse complete message", "error", err.Error())
+		return
+	}
+	if msg.Module != "" && msg.Module != m.mod.Name() {
+		return
+	}
+	if msg.ID == "" {
+		m.log.Error("Could not complete task", "error", "id is required")
+		return
+	}
+
+	m.completeTask(ctx, msg.ID)
+}
+
+// completeTask marks the task with the given id done in its source and
+// removes it from the events.
+func (m *Module) completeTask(ctx context.Context, id string) {
+	src, uid, err := m.taskSource(id)
+	if err != nil {
+		m.log.Error("Could not complete task", "id", id, "error", err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, src.timeout)
+	err = src.api.(taskCompleter).complete(ctx, uid)
+	cancel()
+	if err != nil {
+		m.log.Error("Could not complete task", "calendar", src.name(), "id", id, "error", err.Error())
+		return
+	}
+	m.log.Info("Completed task", "calendar", src.name(), "id", id)
+
+	m.mu.Lock()
+	src.events = slices.DeleteFunc(slices.Clone(src.events), func(evnt ical.Event) bool {
+		return evnt.UID == uid
+	})
+	m.updateEvents()
+	m.mu.Unlock()
+
+	m.render()
+}
+
+// taskSource returns the source of the task with the given id and the
+// uid of the task, if the source allows completing tasks.
+func (m *Module) taskSource(id string) (*source, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, src := range m.sources {
+		for _, evnt := range src.events {
+			if eventID(evnt) != id {
+				continue
+			}
+			if _, ok := src.api.(taskCompleter); !ok || !src.cal.CompleteTasks || evnt.Kind != kindTask {
+				return nil, "", fmt.Errorf("calendar %q does not allow completing tasks", src.name())
+			}
+			return src, evnt.UID, nil
+		}
+	}
+	return nil, "", errors.New("task not found")
+}
@@ -0,0 +1,409 @@
+package main
+
+import (
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+var version = "dev"
+
+// Config is the module configuration.
+type Config struct {
+	Timezone  string     `yaml:"timezone"`
+	Calendars []Calendar `yaml:"calendars"`
+
+	SecondaryTimezone string         `yaml:"secondaryTimezone"`
+	DetectTimezone    DetectTimezone `yaml:"detectTimezone"`
+
+	MaxDays   int `yaml:"maxDays"`
+	MaxEvents int `yaml:"maxEvents"`
+
+	BusinessDays      bool         `yaml:"businessDays"`
+	CollapseRecurring bool         `yaml:"collapseRecurring"`
+	WorkingHours      WorkingHours `yaml:"workingHours"`
+	QuietHours        QuietHours   `yaml:"quietHours"`
+
+	Interval time.Duration `yaml:"interval"`
+	Timeout  time.Duration `yaml:"timeout"`
+
+	BackoffAfter int           `yaml:"backoffAfter"`
+	MaxBackoff   time.Duration `yaml:"maxBackoff"`
+
+	UserAgent string `yaml:"userAgent"`
+
+	ShowErrors  bool   `yaml:"showErrors"`
+	ShowStatus  bool   `yaml:"showStatus"`
+	Debug       bool   `yaml:"debug"`
+	DebugAddr   string `yaml:"debugAddr"`
+	Store       bool   `yaml:"store"`
+	SharedCache bool   `yaml:"sharedCache"`
+
+	ExpandTimeout time.Duration `yaml:"expandTimeout"`
+	PulseBefore   time.Duration `yaml:"pulseBefore"`
+	Alarms        Alarms        `yaml:"alarms"`
+	Chime         Chime         `yaml:"chime"`
+	NextEvent     NextEvent     `yaml:"nextEvent"`
+
+	HideIfEmptyWithin time.Duration `yaml:"hideIfEmptyWithin"`
+
+	Scale    float64 `yaml:"scale"`
+	FontSize string  `yaml:"fontSize"`
+
+	EventTemplate string  `yaml:"eventTemplate"`
+	Theme         string  `yaml:"theme"`
+	IconFont      string  `yaml:"iconFont"`
+	Icons         []Icon  `yaml:"icons"`
+	Colors        []Color `yaml:"colors"`
+	CSS           string  `yaml:"css"`
+	CSSURL        string  `yaml:"cssUrl"`
+
+	View           string        `yaml:"view"`
+	Views          []string      `yaml:"views"`
+	RotateInterval time.Duration `yaml:"rotateInterval"`
+	PageIndicator  string        `yaml:"pageIndicator"`
+	RibbonHours    int           `yaml:"ribbonHours"`
+	DisplayProfile string        `yaml:"displayProfile"`
+	Locale         string        `yaml:"locale"`
+
+	SecondaryCalendar string `yaml:"secondaryCalendar"`
+	WeekStart         string `yaml:"weekStart"`
+
+	MaxRedirects int      `yaml:"maxRedirects"`
+	AllowedHosts []string `yaml:"allowedHosts"`
+	MaxFeedSize  int64    `yaml:"maxFeedSize"`
+
+	RateLimit float64 `yaml:"rateLimit"`
+
+	MaxIdleConns    int           `yaml:"maxIdleConns"`
+	IdleConnTimeout time.Duration `yaml:"idleConnTimeout"`
+
+	Transforms []Transform `yaml:"transforms"`
+
+	Home         Home          `yaml:"home"`
+	Astro        Astro         `yaml:"astro"`
+	StaticEvents []StaticEvent `yaml:"staticEvents"`
+	Timetables   []Timetable   `yaml:"timetables"`
+
+	Anniversaries []Anniversary `yaml:"anniversaries"`
+
+	Travel   Travel   `yaml:"travel"`
+	FreeBusy FreeBusy `yaml:"freeBusy"`
+	Notify   []Notify `yaml:"notify"`
+	MQTT     MQTT     `yaml:"mqtt"`
+
+	Profiles []Profile `yaml:"profiles"`
+
+	Fixture Fixture `yaml:"fixture"`
+	Demo    bool    `yaml:"demo"`
+}
+
+// DetectTimezone configures detecting the timezone when none is set.
+type DetectTimezone struct {
+	Enabled  bool   `yaml:"enabled"`
+	GeoIPURL string `yaml:"geoIpUrl"`
+}
+
+// WorkingHours is a daily range of working hours.
+type WorkingHours struct {
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+}
+
+// QuietHours is a daily range of time during which little or nothing is rendered.
+type QuietHours struct {
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+	Mode  string `yaml:"mode"`
+}
+
+// Alarms configures the reminders shown when event alarms trigger.
+type Alarms struct {
+	Show    bool          `yaml:"show"`
+	Publish bool          `yaml:"publish"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Chime configures the message published before events start, e.g.
+// for an audio module to play a sound.
+type Chime struct {
+	Topic    string        `yaml:"topic"`
+	Sound    string        `yaml:"sound"`
+	LeadTime time.Duration `yaml:"leadTime"`
+}
+
+// NextEvent configures the message published with the next event after
+// events are loaded, e.g. for a clock module to show it under the time.
+type NextEvent struct {
+	Topic string `yaml:"topic"`
+}
+
+// Profile is a named set of calendars, filters and view that can be
+// switched to by schedule or message.
+type Profile struct {
+	Name      string   `yaml:"name"`
+	Calendars []string `yaml:"calendars"`
+	Exclude   []string `yaml:"exclude"`
+	View      string   `yaml:"view"`
+
+	// Days, Start and End schedule the profile. A profile without a
+	// schedule is only switched to by message.
+	Days  []string `yaml:"days"`
+	Start string   `yaml:"start"`
+	End   string   `yaml:"end"`
+}
+
+// Fixture configures the fixture mode, in which calendars may be loaded
+// from local files and the current time is pinned.
+type Fixture struct {
+	Now string `yaml:"now"`
+}
+
+// Auth is the authentication of a calendar.
+type Auth struct {
+	Type string `yaml:"type"`
+
+	// Username and Password configure basic and digest auth.
+	Username     string `yaml:"username"`
+	Password     string `yaml:"password"`
+	PasswordFile string `yaml:"passwordFile"`
+
+	// Token configures bearer auth, and Key the API key sent with
+	// the token of Trello calendars.
+	Token     string `yaml:"token"`
+	TokenFile string `yaml:"tokenFile"`
+	Key       string `yaml:"key"`
+
+	// TokenURL, ClientID, ClientSecret and Scopes configure OAuth 2
+	// with the client credentials grant.
+	TokenURL         string   `yaml:"tokenUrl"`
+	ClientID         string   `yaml:"clientId"`
+	ClientSecret     string   `yaml:"clientSecret"`
+	ClientSecretFile string   `yaml:"clientSecretFile"`
+	Scopes           []string `yaml:"scopes"`
+}
+
+// Icon is a keyword icon mapping.
+type Icon struct {
+	Pattern string `yaml:"pattern"`
+	Icon    string `yaml:"icon"`
+}
+
+// Color is a keyword color rule.
+type Color struct {
+	Pattern string `yaml:"pattern"`
+	Color   string `yaml:"color"`
+	Class   string `yaml:"class"`
+}
+
+// Transform is a built-in event transformer configuration.
+type Transform struct {
+	Type    string `yaml:"type"`
+	Pattern string `yaml:"pattern"`
+	Replace string `yaml:"replace"`
+}
+
+// Home is the location distances to events are measured from.
+type Home struct {
+	Latitude  float64 `yaml:"latitude"`
+	Longitude float64 `yaml:"longitude"`
+	Units     string  `yaml:"units"`
+}
+
+// Astro is an astronomical events configuration.
+type Astro struct {
+	Latitude   float64 `yaml:"latitude"`
+	Longitude  float64 `yaml:"longitude"`
+	Sunrise    bool    `yaml:"sunrise"`
+	Sunset     bool    `yaml:"sunset"`
+	MoonPhases bool    `yaml:"moonPhases"`
+}
+
+// StaticEvent is an event defined in the configuration.
+type StaticEvent struct {
+	Title    string `yaml:"title"`
+	Location string `yaml:"location"`
+	Date     string `yaml:"date"`
+	Time     string `yaml:"time"`
+	Repeat   string `yaml:"repeat"`
+	AllDay   bool   `yaml:"allDay"`
+}
+
+// Timetable is a weekly timetable configuration.
+type Timetable struct {
+	Name  string `yaml:"name"`
+	Slots []Slot `yaml:"slots"`
+}
+
+// Slot is a weekly timetable slot.
+type Slot struct {
+	Day      string `yaml:"day"`
+	Start    string `yaml:"start"`
+	End      string `yaml:"end"`
+	Title    string `yaml:"title"`
+	Location string `yaml:"location"`
+}
+
+// Anniversary is a date to count down to or count the years since.
+type Anniversary struct {
+	Name string `yaml:"name"`
+	Date string `yaml:"date"`
+}
+
+// MQTT is an MQTT broker configuration.
+type MQTT struct {
+	URL         string        `yaml:"url"`
+	ClientID    string        `yaml:"clientId"`
+	Username    string        `yaml:"username"`
+	Password    string        `yaml:"password"`
+	KeepAlive   time.Duration `yaml:"keepAlive"`
+	NextTopic   string        `yaml:"nextTopic"`
+	AgendaTopic string        `yaml:"agendaTopic"`
+
+	HomeAssistant   bool   `yaml:"homeAssistant"`
+	DiscoveryPrefix string `yaml:"discoveryPrefix"`
+	ObjectID        string `yaml:"objectId"`
+}
+
+// Travel is a travel time routing configuration.
+type Travel struct {
+	Provider   string        `yaml:"provider"`
+	URL        string        `yaml:"url"`
+	GeocodeURL string        `yaml:"geocodeUrl"`
+	APIKey     string        `yaml:"apiKey"`
+	Profile    string        `yaml:"profile"`
+	Latitude   float64       `yaml:"latitude"`
+	Longitude  float64       `yaml:"longitude"`
+	Lookahead  time.Duration `yaml:"lookahead"`
+	WarnBefore time.Duration `yaml:"warnBefore"`
+}
+
+// FreeBusy is the configuration of the combined availability of calendars
+// from the Google Calendar FreeBusy API.
+type FreeBusy struct {
+	URL         string   `yaml:"url"`
+	APIKey      string   `yaml:"apiKey"`
+	AccessToken string   `yaml:"accessToken"`
+	Calendars   []string `yaml:"calendars"`
+	Hours       int      `yaml:"hours"`
+}
+
+// Notify is a webhook notification configuration.
+type Notify struct {
+	URL      string        `yaml:"url"`
+	LeadTime time.Duration `yaml:"leadTime"`
+}
+
+// Calendar is a calendar configuration.
+type Calendar struct {
+	Name      string        `yaml:"name"`
+	Type      string        `yaml:"type"`
+	Preset    string        `yaml:"preset"`
+	URL       string        `yaml:"url"`
+	Icon      string        `yaml:"icon"`
+	MaxEvents int           `yaml:"maxEvents"`
+	Timeout   time.Duration `yaml:"timeout"`
+	Interval  time.Duration `yaml:"interval"`
+
+	// Enabled turns the calendar off when false, keeping its configuration.
+	Enabled *bool `yaml:"enabled"`
+
+	// Timezone is the timezone of floating times in the calendar,
+	// overriding the timezone declared by the calendar.
+	Timezone string `yaml:"timezone"`
+
+	// OnlyCalendars selects the sub-calendars of an aggregated feed
+	// to show by name, dropping the events of all others.
+	OnlyCalendars []string `yaml:"onlyCalendars"`
+
+	// ChimeBefore overrides the chime lead time for the calendar.
+	ChimeBefore time.Duration `yaml:"chimeBefore"`
+
+	// Collection is the calendar of CalDAV calendars to show.
+	Collection string `yaml:"collection"`
+
+	// QuickAdd allows events to be added to the CalDAV calendar
+	// by message.
+	QuickAdd bool `yaml:"quickAdd"`
+
+	// Auth configures the authentication of the calendar.
+	Auth Auth `yaml:"auth"`
+
+	// Repos and Issues configure GitHub calendars.
+	Repos  []string `yaml:"repos"`
+	Issues bool     `yaml:"issues"`
+
+	// Query is the JQL query of Jira calendars or the filter query
+	// of Todoist calendars.
+	Query string `yaml:"query"`
+
+	// CompleteTasks allows the tasks of Todoist and iCloud calendars
+	// to be marked done.
+	CompleteTasks bool `yaml:"completeTasks"`
+
+	// Boards and Lists configure Trello calendars.
+	Boards []string `yaml:"boards"`
+	Lists  []string `yaml:"lists"`
+}
+
+// NewConfig creates a default configuration for the module.
+func NewConfig() Config {
+	return Config{
+		MaxDays:   5,
+		MaxEvents: 20,
+		Interval:  30 * time.Minute,
+		Timeout:   30 * time.Second,
+
+		BackoffAfter: 3,
+		MaxBackoff:   6 * time.Hour,
+
+		UserAgent: "glasslabs-calendar/" + version,
+
+		ShowErrors: true,
+
+		ExpandTimeout: 15 * time.Second,
+		Alarms: Alarms{
+			Timeout: 5 * time.Minute,
+		},
+		Chime: Chime{
+			Topic: "calendar.chime",
+		},
+		NextEvent: NextEvent{
+			Topic: "calendar.next",
+		},
+
+		Scale: 1,
+
+		View:           "agenda",
+		RotateInterval: 30 * time.Second,
+		PageIndicator:  "dots",
+		RibbonHours:    12,
+		WeekStart:      "monday",
+
+		MaxRedirects: 5,
+		MaxFeedSize:  ical.DefaultMaxBodySize,
+
+		MaxIdleConns:    10,
+		IdleConnTimeout: 90 * time.Second,
+
+		Home: Home{
+			Units: "km",
+		},
+		Travel: Travel{
+			Lookahead:  12 * time.Hour,
+			WarnBefore: 10 * time.Minute,
+		},
+		FreeBusy: FreeBusy{
+			Hours: 12,
+		},
+
+		MQTT: MQTT{
+			KeepAlive:   time.Minute,
+			NextTopic:   "glasslabs/calendar/next",
+			AgendaTopic: "glasslabs/calendar/agenda",
+
+			DiscoveryPrefix: "homeassistant",
+			ObjectID:        "mirror_next_event",
+		},
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"sort"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/render"
+)
+
+// dayIndex indexes events sorted by time by the day they start on, so
+// the events of a day are found without scanning every event.
+type dayIndex struct {
+	// days are the days with events in ascending order, and starts
+	// the index of the first event of each day.
+	days   []time.Time
+	starts []int
+	n      int
+}
+
+// newDayIndex returns the day index of the events, which must be sorted
+// by time. Days are in the location of the event times.
+func newDayIndex(events []render.Event) dayIndex {
+	idx := dayIndex{n: len(events)}
+	for i, evnt := range events {
+		day := startOfDay(evnt.Time)
+		if len(idx.days) > 0 && idx.days[len(idx.days)-1].Equal(day) {
+			continue
+		}
+		idx.days = append(idx.days, day)
+		idx.starts = append(idx.starts, i)
+	}
+	return idx
+}
+
+// day returns the range of indexes of the events on the day of t in loc.
+func (x dayIndex) day(t time.Time, loc *time.Location) (from, to int) {
+	day := startOfDay(t.In(loc))
+	i := sort.Search(len(x.days), func(i int) bool { return !x.days[i].Before(day) })
+	if i == len(x.days) || !x.days[i].Equal(day) {
+		return 0, 0
+	}
+	return x.starts[i], x.end(i)
+}
+
+// end returns the index after the last event of the i-th day.
+func (x dayIndex) end(i int) int {
+	if i+1 < len(x.starts) {
+		return x.starts[i+1]
+	}
+	return x.n
+}
+
+// weekStarts returns the indexes of the first events of each week after
+// the first, for weeks starting on weekStart.
+func (x dayIndex) weekStarts(weekStart time.Weekday) []int {
+	var res []int
+	for i := 1; i < len(x.days); i++ {
+		if !startOfWeek(x.days[i], weekStart).Equal(startOfWeek(x.days[i-1], weekStart)) {
+			res = append(res, x.starts[i])
+		}
+	}
+	return res
+}
+
+// startOfDay returns the start of the day of t in its location.
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
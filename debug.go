@@ -0,0 +1,50 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/json"
+	"syscall/js"
+)
+
+// The module runs in the browser and cannot listen for connections,
+// so debug information is exposed on the window instead, with the
+// credentials in the configuration redacted:
+//
+//	JSON.parse(window.calendarDebug["simple-calendar"]())
+
+// exposeDebug registers the debug function on the window.
+func (m *Module) exposeDebug() {
+	obj := js.Global().Get("calendarDebug")
+	if obj.Type() != js.TypeObject {
+		obj = js.Global().Get("Object").New()
+		js.Global().Set("calendarDebug", obj)
+	}
+
+	obj.Set(m.mod.Name(), js.FuncOf(func(js.Value, []js.Value) any {
+		b, err := json.Marshal(m.debugInfo())
+		if err != nil {
+			return err.Error()
+		}
+		return string(b)
+	}))
+}
+
+func (m *Module) debugInfo() debugInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	health, updated := m.health(m.clock.Now())
+	info := debugInfo{
+		Health:  health,
+		Updated: updated,
+		Config:  m.cfg.redacted(),
+		Sources: make([]debugSource, 0, len(m.sources)),
+		Events:  m.events,
+		History: m.history,
+	}
+	for _, src := range m.sources {
+		info.Sources = append(info.Sources, newDebugSource(src))
+	}
+	return info
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/render"
+)
+
+// debugInfo is the debug information of the module.
+type debugInfo struct {
+	Health  string         `json:"health,omitempty"`
+	Updated time.Time      `json:"updated"`
+	Config  Config         `json:"config"`
+	Sources []debugSource  `json:"sources"`
+	Events  []render.Event `json:"events"`
+	History []change       `json:"history,omitempty"`
+}
+
+// debugSource is the fetch status of a source.
+type debugSource struct {
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+	Interval  string    `json:"interval"`
+	LastFetch time.Time `json:"lastFetch"`
+	Failures  int       `json:"failures"`
+	Error     string    `json:"error,omitempty"`
+	Events    int       `json:"events"`
+}
+
+// newDebugSource returns the fetch status of the source. Calendar URLs
+// are redacted, also in the error, as they may hold credentials.
+func newDebugSource(src *source) debugSource {
+	ds := debugSource{
+		Name:      src.name(),
+		URL:       redactURL(src.cal.URL),
+		Interval:  src.interval.String(),
+		LastFetch: src.fetched,
+		Failures:  src.failures,
+		Events:    len(src.events),
+	}
+	if src.err != nil {
+		ds.Error = redactError(src.err)
+	}
+	return ds
+}
@@ -0,0 +1,49 @@
+//go:build !js
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDebugSourceRedactsURL(t *testing.T) {
+	// The feed redirects to a closed server, so the error holds both URLs.
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		http.Redirect(rw, req, closed.URL+"/feed.ics?token=s3cret-redirect", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	cfg := NewConfig()
+	cfg.Calendars = []Calendar{{URL: srv.URL + "/feed.ics?token=s3cret-feed"}}
+	f := newFetcher(cfg)
+	srcs, err := newSources(cfg, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := srcs[0]
+
+	start := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	_, src.err = src.load(context.Background(), f, start, start.AddDate(0, 0, 7))
+	if src.err == nil {
+		t.Fatal("got no error loading the calendar")
+	}
+
+	b, err := json.Marshal(newDebugSource(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "s3cret") {
+		t.Errorf("debug source holds the token: %s", b)
+	}
+	if !strings.Contains(string(b), "token=REDACTED") {
+		t.Errorf("debug source does not hold the redacted url: %s", b)
+	}
+}
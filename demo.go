@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+// demoCalendar is the calendar of the demo events.
+var demoCalendar = Calendar{Name: "Demo", URL: "demo:"}
+
+// demoSource synthesizes a realistic spread of events relative to the
+// current time, to evaluate layouts and themes without a calendar.
+type demoSource struct {
+	tz *time.Location
+}
+
+func (s demoSource) events(_ context.Context, start, end time.Time) ([]ical.Event, error) {
+	now := start.In(s.tz)
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, s.tz)
+	weekly := func(days string) map[string]string {
+		return map[string]string{"FREQ": "WEEKLY", "BYDAY": days}
+	}
+
+	var evnts []ical.Event
+	add := func(evnt ical.Event) {
+		if evnt.End.After(start) && evnt.Start.Before(end) {
+			evnt.UID = "demo-" + evnt.UID + "-" + evnt.Start.Format("20060102") + "@glasslabs-calendar"
+			evnts = append(evnts, evnt)
+		}
+	}
+
+	// An event in progress and an imminent one.
+	inProgress := now.Truncate(15 * time.Minute).Add(-15 * time.Minute)
+	add(ical.Event{
+		UID:     "focus",
+		Summary: "Focus time",
+		Start:   inProgress,
+		End:     inProgress.Add(45 * time.Minute),
+	})
+	imminent := now.Truncate(5 * time.Minute).Add(15 * time.Minute)
+	add(ical.Event{
+		UID:      "coffee",
+		Summary:  "Coffee with Alex",
+		Location: "Corner Café",
+		Start:    imminent,
+		End:      imminent.Add(30 * time.Minute),
+		Alarms:   []ical.Alarm{{Offset: -10 * time.Minute, Action: "DISPLAY"}},
+	})
+
+	for day := today; day.Before(end); day = day.AddDate(0, 0, 1) {
+		n := int(day.Sub(today).Hours() / 24)
+		at := func(hour, minute int) time.Time {
+			return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, s.tz)
+		}
+
+		switch day.Weekday() {
+		case time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday:
+			add(ical.Event{
+				UID:            "standup",
+				Summary:        "Stand-up",
+				Location:       "Video call",
+				Start:          at(9, 30),
+				End:            at(9, 45),
+				IsRecurring:    true,
+				RecurrenceRule: weekly("MO,TU,WE,TH,FR"),
+			})
+		case time.Saturday:
+			add(ical.Event{
+				UID:            "market",
+				Summary:        "Farmers market",
+				Start:          at(10, 0),
+				End:            at(12, 0),
+				IsRecurring:    true,
+				RecurrenceRule: weekly("SA"),
+			})
+		}
+		switch day.Weekday() {
+		case time.Tuesday, time.Thursday:
+			add(ical.Event{
+				UID:            "gym",
+				Summary:        "Gym",
+				Start:          at(18, 0),
+				End:            at(19, 0),
+				IsRecurring:    true,
+				RecurrenceRule: weekly("TU,TH"),
+			})
+		case time.Wednesday:
+			add(ical.Event{
+				UID:            "bins",
+				Summary:        "Bin day",
+				Start:          day,
+				End:            day.AddDate(0, 0, 1),
+				AllDay:         true,
+				IsRecurring:    true,
+				RecurrenceRule: weekly("WE"),
+			})
+		case time.Friday:
+			add(ical.Event{
+				UID:     "trip",
+				Summary: "Weekend away",
+				Start:   day,
+				End:     day.AddDate(0, 0, 3),
+				AllDay:  true,
+			})
+		}
+
+		switch n {
+		case 0:
+			add(ical.Event{
+				UID:         "review",
+				Summary:     "Project review",
+				Description: "Go through the plan for next quarter.",
+				Location:    "Room 4",
+				Start:       at(14, 0),
+				End:         at(15, 0),
+				Attendees:   []string{"Alex", "Sam", "Jo"},
+			})
+		case 1:
+			add(ical.Event{
+				UID:      "dinner",
+				Summary:  "Dinner with friends",
+				Location: "Luigi's",
+				Start:    at(19, 30),
+				End:      at(22, 0),
+			})
+		case 3:
+			add(ical.Event{
+				UID:     "birthday",
+				Summary: "Sam's birthday",
+				Start:   day,
+				End:     day.AddDate(0, 0, 1),
+				AllDay:  true,
+			})
+		case 4:
+			add(ical.Event{
+				UID:      "dentist",
+				Summary:  "Dentist",
+				Location: "High Street Dental",
+				Start:    at(8, 15),
+				End:      at(8, 45),
+			})
+		}
+	}
+
+	ical.Sort(evnts)
+	return evnts, nil
+}
@@ -0,0 +1,79 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// dismissMessage is received on the "calendar.dismiss" topic.
+type dismissMessage struct {
+	Module string    `json:"module"`
+	ID     string    `json:"id"`
+	Until  time.Time `json:"until"`
+}
+
+// handleDismiss hides an event, or snoozes it until the given time.
+func (m *Module) handleDismiss(data []byte) {
+	var msg dismissMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		m.log.Error("Could not parse dismiss message", "error", err.Error())
+		return
+	}
+	if msg.Module != "" && msg.Module != m.mod.Name() {
+		return
+	}
+	if msg.ID == "" {
+		m.log.Error("Could not dismiss event", "error", "id is required")
+		return
+	}
+
+	m.dismiss(msg.ID, msg.Until)
+}
+
+// dismiss hides the event with the given id until the given time, or
+// for good if the time is zero. Dismissed events are persisted.
+func (m *Module) dismiss(id string, until time.Time) {
+	m.mu.Lock()
+	m.dismissed[id] = until
+	if err := saveJSON(m.prefs, "dismissed", m.dismissed); err != nil {
+		m.log.Error("Could not store dismissed events", "error", err.Error())
+	}
+	m.updateEvents()
+	m.mu.Unlock()
+
+	m.render()
+}
+
+// isDismissed reports whether the event with the given id is hidden.
+// Must be called with the lock held.
+func (m *Module) isDismissed(id string, now time.Time) bool {
+	until, ok := m.dismissed[id]
+	return ok && (until.IsZero() || until.After(now))
+}
+
+// pruneDismissed removes elapsed snoozes and dismissals of past events,
+// returning true if any snoozes elapsed. Must be called with the lock held.
+func (m *Module) pruneDismissed(now time.Time) bool {
+	var snoozed, pruned bool
+	for id, until := range m.dismissed {
+		start, ok := eventIDStart(id)
+		switch {
+		case !until.IsZero() && !until.After(now):
+			snoozed = true
+		case ok && start.Add(24*time.Hour).Before(now):
+		default:
+			continue
+		}
+		delete(m.dismissed, id)
+		pruned = true
+	}
+
+	if pruned {
+		if err := saveJSON(m.prefs, "dismissed", m.dismissed); err != nil {
+			m.log.Error("Could not store dismissed events", "error", err.Error())
+		}
+	}
+	return snoozed
+}
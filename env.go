@@ -0,0 +1,51 @@
+package main
+
+import "regexp"
+
+// envRE matches environment variable references, e.g. "${CALENDAR_TOKEN}".
+var envRE = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// envFields returns the URLs and secrets of the configuration that may
+// reference environment variables.
+func (c *Config) envFields() []*string {
+	var fields []*string
+	for i := range c.Calendars {
+		cal := &c.Calendars[i]
+		fields = append(fields,
+			&cal.URL, &cal.Auth.Username, &cal.Auth.Password, &cal.Auth.PasswordFile,
+			&cal.Auth.Token, &cal.Auth.TokenFile, &cal.Auth.Key,
+			&cal.Auth.TokenURL, &cal.Auth.ClientID, &cal.Auth.ClientSecret, &cal.Auth.ClientSecretFile,
+		)
+	}
+	for i := range c.Notify {
+		fields = append(fields, &c.Notify[i].URL)
+	}
+	return append(fields,
+		&c.Travel.URL, &c.Travel.APIKey,
+		&c.FreeBusy.URL, &c.FreeBusy.APIKey, &c.FreeBusy.AccessToken,
+		&c.MQTT.URL, &c.MQTT.Username, &c.MQTT.Password,
+	)
+}
+
+// secretFile is a calendar secret configured as a file.
+type secretFile struct {
+	cal    Calendar
+	name   string
+	path   string
+	secret *string
+}
+
+// secretFiles returns the calendar passwords and tokens that may be
+// configured as files.
+func (c *Config) secretFiles() []secretFile {
+	var files []secretFile
+	for i := range c.Calendars {
+		cal := &c.Calendars[i]
+		files = append(files,
+			secretFile{cal: *cal, name: "auth.password", path: cal.Auth.PasswordFile, secret: &cal.Auth.Password},
+			secretFile{cal: *cal, name: "auth.token", path: cal.Auth.TokenFile, secret: &cal.Auth.Token},
+			secretFile{cal: *cal, name: "auth.clientSecret", path: cal.Auth.ClientSecretFile, secret: &cal.Auth.ClientSecret},
+		)
+	}
+	return files
+}
@@ -0,0 +1,45 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// expandEnv replaces the environment variable references in the URLs and
+// secrets of the configuration, so secrets need not be kept in it.
+func (c *Config) expandEnv() error {
+	var err error
+	for _, s := range c.envFields() {
+		*s = envRE.ReplaceAllStringFunc(*s, func(ref string) string {
+			name := envRE.FindStringSubmatch(ref)[1]
+			val, ok := os.LookupEnv(name)
+			if !ok && err == nil {
+				err = fmt.Errorf("environment variable %q is not set", name)
+			}
+			return val
+		})
+	}
+	return err
+}
+
+// readSecretFiles reads the calendar passwords and tokens configured
+// as files, such as Docker or Kubernetes secret mounts.
+func (c *Config) readSecretFiles() error {
+	for _, f := range c.secretFiles() {
+		if f.path == "" {
+			continue
+		}
+		if *f.secret != "" {
+			return fmt.Errorf("calendar %q: %s and %sFile are mutually exclusive", calendarName(f.cal), f.name, f.name)
+		}
+		b, err := os.ReadFile(f.path) //nolint:gosec // The path is provided by the user.
+		if err != nil {
+			return fmt.Errorf("calendar %q: reading %s file: %w", calendarName(f.cal), f.name, err)
+		}
+		*f.secret = strings.TrimRight(string(b), "\r\n")
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+//go:build js && wasm
+
+package main
+
+import "fmt"
+
+// checkHostOnly returns an error when the configuration references
+// environment variables or secret files, which the browser cannot read.
+func (c *Config) checkHostOnly() error {
+	for _, s := range c.envFields() {
+		if m := envRE.FindStringSubmatch(*s); m != nil {
+			return fmt.Errorf("environment variable %q cannot be read in the browser, "+
+				"set the value in the configuration or use the standalone build", m[1])
+		}
+	}
+	for _, f := range c.secretFiles() {
+		if f.path != "" {
+			return fmt.Errorf("calendar %q: %sFile cannot be read in the browser, "+
+				"set %s in the configuration or use the standalone build", calendarName(f.cal), f.name, f.name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"honnef.co/go/js/dom/v2"
+)
+
+// handleClick toggles the details of the clicked event, hides the
+// event when its dismiss button is clicked, marks the task done when its
+// complete button is clicked, or acknowledges a reminder.
+func (m *Module) handleClick(e dom.Event) {
+	target := e.Target()
+	if target == nil {
+		return
+	}
+	if elem := target.Closest("[data-alarm]"); elem != nil {
+		go m.acknowledgeAlarm(elem.GetAttribute("data-alarm"))
+		return
+	}
+	if btn := target.Closest("[data-dismiss]"); btn != nil {
+		id := btn.GetAttribute("data-dismiss")
+		if id != "" {
+			go m.dismiss(id, time.Time{})
+		}
+		return
+	}
+
+	if btn := target.Closest("[data-complete]"); btn != nil {
+		id := btn.GetAttribute("data-complete")
+		if id != "" {
+			go m.completeTask(context.Background(), id)
+		}
+		return
+	}
+
+	row := target.Closest("[data-event]")
+	if row == nil {
+		return
+	}
+	id := row.GetAttribute("data-event")
+	if id == "" {
+		return
+	}
+
+	// Rendering must not block the event handler.
+	go m.toggleExpanded(id)
+}
+
+// toggleExpanded expands the event with the given id, or collapses it
+// if already expanded. Expanded events collapse after the expand timeout.
+func (m *Module) toggleExpanded(id string) {
+	m.mu.Lock()
+	if m.collapse != nil {
+		m.collapse.Stop()
+		m.collapse = nil
+	}
+	if m.expanded == id {
+		m.expanded = ""
+	} else {
+		m.expanded = id
+		if m.cfg.ExpandTimeout > 0 {
+			m.collapse = time.AfterFunc(m.cfg.ExpandTimeout, func() {
+				m.mu.Lock()
+				if m.expanded == id {
+					m.expanded = ""
+				}
+				m.mu.Unlock()
+
+				m.render()
+			})
+		}
+	}
+	m.mu.Unlock()
+
+	m.render()
+}
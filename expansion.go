@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+// expansion is a calendar feed parsed and its recurring events expanded
+// within a window of whole days.
+type expansion struct {
+	sum        [sha256.Size]byte
+	validators ical.Validators
+	start, end time.Time
+	cal        *ical.Calendar
+}
+
+// expansionDays returns the whole days covering the window between start
+// and end.
+func expansionDays(start, end time.Time) (time.Time, time.Time) {
+	return start.Truncate(24 * time.Hour), end.Truncate(24 * time.Hour).Add(24 * time.Hour)
+}
+
+// validators returns the validators of the feed when its expansion covers
+// the window between start and end, so the feed is only downloaded again
+// when it has changed.
+func (s *source) validators(start, end time.Time) ical.Validators {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dayStart, dayEnd := expansionDays(start, end)
+	if exp := s.expansion; exp != nil && exp.start.Equal(dayStart) && exp.end.Equal(dayEnd) {
+		return exp.validators
+	}
+	return ical.Validators{}
+}
+
+// parse returns the events of the calendar feed within the window between
+// start and end. Expanding recurring events is slow for large feeds, so
+// the feed is expanded over whole days and the expansion is reused until
+// the feed or the days of the window change. A nil feed means the server
+// reported it unchanged, reusing the expansion without parsing.
+func (s *source) parse(b []byte, v ical.Validators, start, end time.Time) (*ical.Calendar, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dayStart, dayEnd := expansionDays(start, end)
+
+	exp := s.expansion
+	switch {
+	case b == nil:
+		if exp == nil || !exp.start.Equal(dayStart) || !exp.end.Equal(dayEnd) {
+			return nil, errors.New("calendar not modified, but not expanded for the window")
+		}
+	default:
+		// Feeds from servers without validators are compared by
+		// their hash instead.
+		sum := sha256.Sum256(b)
+		if exp == nil || exp.sum != sum || !exp.start.Equal(dayStart) || !exp.end.Equal(dayEnd) {
+			cal, err := ical.ParseInLocation(bytes.NewReader(b), dayStart, dayEnd, s.tz)
+			if err != nil {
+				return nil, err
+			}
+			exp = &expansion{sum: sum, start: dayStart, end: dayEnd, cal: cal}
+		}
+		// A feed read from the cache has no validators, leaving those
+		// of the unchanged feed.
+		if v != (ical.Validators{}) || exp != s.expansion {
+			exp.validators = v
+		}
+		s.expansion = exp
+	}
+
+	evnts := make([]ical.Event, 0, len(exp.cal.Events))
+	for _, evnt := range exp.cal.Events {
+		if evnt.End.After(start) && evnt.Start.Before(end) && s.inSubCalendar(evnt, exp.cal.Name) {
+			evnts = append(evnts, evnt)
+		}
+	}
+	return &ical.Calendar{Name: exp.cal.Name, Events: evnts, Cancelled: exp.cal.Cancelled}, nil
+}
@@ -0,0 +1,72 @@
+//go:build !js
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSourceLoadNotModified(t *testing.T) {
+	feed, err := os.ReadFile("testdata/family.ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var full, notModified int
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("ETag", `"v1"`)
+		if req.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			rw.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		_, _ = rw.Write(feed)
+	}))
+	defer srv.Close()
+
+	cfg := NewConfig()
+	cfg.Timezone = "Europe/London"
+	cfg.Calendars = []Calendar{{URL: srv.URL}}
+	f := newFetcher(cfg)
+	srcs, err := newSources(cfg, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := srcs[0]
+
+	start := time.Date(2024, 6, 3, 7, 0, 0, 0, time.UTC)
+	end := start.Add(7 * 24 * time.Hour)
+	first, err := src.load(context.Background(), f, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := src.expansion
+
+	second, err := src.load(context.Background(), f, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if full != 1 || notModified != 1 {
+		t.Fatalf("got %d full and %d not modified responses, want 1 of each", full, notModified)
+	}
+	if src.expansion != exp {
+		t.Error("the unchanged feed was expanded again")
+	}
+	if got, want := eventIDs(second.Events), eventIDs(first.Events); len(got) == 0 || !slices.Equal(got, want) {
+		t.Errorf("got events %q, want %q", got, want)
+	}
+
+	// A new day needs the feed expanded over a new window.
+	if _, err = src.load(context.Background(), f, start.Add(24*time.Hour), end.Add(24*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if full != 2 {
+		t.Errorf("got %d full responses, want the feed downloaded again for a new day", full)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// fixturePath returns the path of a file url, either relative as in
+// "file:testdata/work.ics" or absolute as in "file:///srv/work.ics".
+func fixturePath(u *url.URL) (string, bool) {
+	if u.Scheme != "file" {
+		return "", false
+	}
+	return cmp.Or(u.Opaque, u.Path), true
+}
+
+// newClock returns the clock of the configuration, which is pinned to
+// the fixture time when set.
+func newClock(cfg Fixture) (Clock, error) {
+	if cfg.Now == "" {
+		return systemClock{}, nil
+	}
+
+	now, err := time.Parse(time.RFC3339, cfg.Now)
+	if err != nil {
+		return nil, fmt.Errorf("parsing fixture time: %w", err)
+	}
+	return fixedClock{now: now}, nil
+}
+
+// validateFixtureURL checks that a calendar may be loaded from the
+// file url.
+func validateFixtureURL(cfg Fixture, cal Calendar, u *url.URL) error {
+	path, _ := fixturePath(u)
+	switch {
+	case cfg.Now == "":
+		return errors.New("file urls require fixture mode")
+	case cal.Type != "":
+		return fmt.Errorf("file urls are not supported by %s calendars", cal.Type)
+	case path == "":
+		return fmt.Errorf("url %q has no path", u)
+	}
+	return nil
+}
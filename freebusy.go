@@ -0,0 +1,171 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+	"github.com/glasslabs/calendar/pkg/render"
+)
+
+// busyPeriod is a period of time in which any calendar is busy.
+type busyPeriod struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// freeBusyClient queries the Google Calendar FreeBusy API for the
+// combined busy periods of a set of calendars, without their events.
+type freeBusyClient struct {
+	cfg FreeBusy
+	f   *ical.Fetcher
+
+	mu   sync.Mutex
+	busy []busyPeriod
+}
+
+func newFreeBusyClient(cfg FreeBusy, f *ical.Fetcher) (*freeBusyClient, error) {
+	if cfg.APIKey == "" && cfg.AccessToken == "" {
+		return nil, errors.New("free busy api key or access token is required")
+	}
+	if cfg.Hours <= 0 {
+		return nil, fmt.Errorf("invalid free busy hours %d", cfg.Hours)
+	}
+	cfg.URL = cmp.Or(cfg.URL, "https://www.googleapis.com/calendar/v3/freeBusy")
+	return &freeBusyClient{cfg: cfg, f: f}, nil
+}
+
+// update queries the busy periods of the calendars from the current hour.
+func (c *freeBusyClient) update(ctx context.Context, now time.Time) error {
+	from := now.Truncate(time.Hour)
+	to := from.Add(time.Duration(c.cfg.Hours) * time.Hour)
+
+	type item struct {
+		ID string `json:"id"`
+	}
+	query := struct {
+		TimeMin time.Time `json:"timeMin"`
+		TimeMax time.Time `json:"timeMax"`
+		Items   []item    `json:"items"`
+	}{TimeMin: from, TimeMax: to}
+	for _, id := range c.cfg.Calendars {
+		query.Items = append(query.Items, item{ID: id})
+	}
+	b, err := json.Marshal(query)
+	if err != nil {
+		return fmt.Errorf("encoding query: %w", err)
+	}
+
+	u, err := url.Parse(c.cfg.URL)
+	if err != nil {
+		return fmt.Errorf("parsing url: %w", err)
+	}
+	if c.cfg.APIKey != "" {
+		q := u.Query()
+		q.Set("key", c.cfg.APIKey)
+		u.RawQuery = q.Encode()
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	if c.cfg.AccessToken != "" {
+		header.Set("Authorization", "Bearer "+c.cfg.AccessToken)
+	}
+	body, err := c.f.Request(ctx, http.MethodPost, u.String(), header, b)
+	if err != nil {
+		return fmt.Errorf("querying free busy: %w", err)
+	}
+
+	var res struct {
+		Calendars map[string]struct {
+			Busy   []busyPeriod `json:"busy"`
+			Errors []struct {
+				Reason string `json:"reason"`
+			} `json:"errors"`
+		} `json:"calendars"`
+	}
+	if err = json.Unmarshal(body, &res); err != nil {
+		return fmt.Errorf("parsing free busy response: %w", err)
+	}
+
+	var (
+		busy []busyPeriod
+		errs []error
+	)
+	for _, id := range c.cfg.Calendars {
+		cal := res.Calendars[id]
+		for _, e := range cal.Errors {
+			errs = append(errs, fmt.Errorf("calendar %q: %s", id, e.Reason))
+		}
+		busy = append(busy, cal.Busy...)
+	}
+
+	c.mu.Lock()
+	c.busy = mergeBusy(busy)
+	c.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// strip returns the availability strip from the current hour.
+func (c *freeBusyClient) strip(now time.Time) *render.FreeBusyStrip {
+	from := now.Truncate(time.Hour)
+	to := from.Add(time.Duration(c.cfg.Hours) * time.Hour)
+	total := to.Sub(from)
+	pos := func(t time.Time) float64 {
+		switch {
+		case t.Before(from):
+			t = from
+		case t.After(to):
+			t = to
+		}
+		return 100 * float64(t.Sub(from)) / float64(total)
+	}
+
+	s := &render.FreeBusyStrip{Now: pos(now)}
+	for t := from; t.Before(to); t = t.Add(time.Hour) {
+		s.Hours = append(s.Hours, render.RibbonHour{Time: t.In(now.Location()), Left: pos(t)})
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, p := range c.busy {
+		if !p.End.After(from) || !p.Start.Before(to) {
+			continue
+		}
+		s.Blocks = append(s.Blocks, render.FreeBusyBlock{
+			Start: p.Start.In(now.Location()),
+			End:   p.End.In(now.Location()),
+			Left:  pos(p.Start),
+			Width: pos(p.End) - pos(p.Start),
+		})
+	}
+	return s
+}
+
+// mergeBusy sorts the busy periods, merging those that overlap or touch.
+func mergeBusy(busy []busyPeriod) []busyPeriod {
+	slices.SortFunc(busy, func(a, b busyPeriod) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	var merged []busyPeriod
+	for _, p := range busy {
+		if n := len(merged); n > 0 && !p.Start.After(merged[n-1].End) {
+			if p.End.After(merged[n-1].End) {
+				merged[n-1].End = p.End
+			}
+			continue
+		}
+		merged = append(merged, p)
+	}
+	return merged
+}
@@ -0,0 +1,67 @@
+//go:build !js
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+func TestFreeBusyClientUpdateKeepsQuery(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		query = req.URL.RawQuery
+		_, _ = rw.Write([]byte(`{"calendars":{"me":{"busy":[{"start":"2024-06-03T10:00:00Z","end":"2024-06-03T11:00:00Z"}]}}}`))
+	}))
+	defer srv.Close()
+
+	cfg := FreeBusy{
+		URL:       srv.URL + "/freeBusy?alt=json",
+		APIKey:    "a&b",
+		Calendars: []string{"me"},
+		Hours:     8,
+	}
+	c, err := newFreeBusyClient(cfg, newFetcher(NewConfig()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2024, 6, 3, 9, 30, 0, 0, time.UTC)
+	if err = c.update(context.Background(), now); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "alt=json&key=a%26b"; query != want {
+		t.Errorf("got query %q, want %q", query, want)
+	}
+	if len(c.busy) != 1 {
+		t.Errorf("got %d busy periods, want 1", len(c.busy))
+	}
+}
+
+func TestFreeBusyClientUpdateLimitsBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		_, _ = rw.Write([]byte(`{"calendars":{"me":{"busy":[]}},"padding":"` + strings.Repeat("x", 2048) + `"}`))
+	}))
+	defer srv.Close()
+
+	appCfg := NewConfig()
+	appCfg.MaxFeedSize = 1024
+	cfg := FreeBusy{URL: srv.URL, APIKey: "key", Calendars: []string{"me"}, Hours: 8}
+	c, err := newFreeBusyClient(cfg, newFetcher(appCfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.update(context.Background(), time.Date(2024, 6, 3, 9, 30, 0, 0, time.UTC))
+	if !errors.Is(err, ical.ErrBodyTooLarge) {
+		t.Errorf("got error %v, want %v", err, ical.ErrBodyTooLarge)
+	}
+}
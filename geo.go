@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+// Distance units.
+const (
+	unitsKilometers = "km"
+	unitsMiles      = "mi"
+)
+
+// earthRadiusKm is the mean radius of the earth in kilometers.
+const earthRadiusKm = 6371.0
+
+// validateUnits checks that the distance units are known.
+func validateUnits(units string) error {
+	switch units {
+	case unitsKilometers, unitsMiles:
+		return nil
+	default:
+		return fmt.Errorf("unknown distance units %q", units)
+	}
+}
+
+// distanceKm returns the great-circle distance between two coordinates
+// in kilometers.
+func distanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := rad(lat2 - lat1)
+	dLon := rad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(rad(lat1))*math.Cos(rad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(min(a, 1)))
+}
+
+// distance returns the formatted straight-line distance from home to
+// the event, or an empty string if the event has no coordinates or no
+// home is configured.
+func (p *pipeline) distance(evnt ical.Event) string {
+	home := p.cfg.Home
+	if evnt.Geo == nil || (home.Latitude == 0 && home.Longitude == 0) {
+		return ""
+	}
+
+	d := distanceKm(home.Latitude, home.Longitude, evnt.Geo.Latitude, evnt.Geo.Longitude)
+	if home.Units == unitsMiles {
+		d /= 1.609344
+	}
+	if d < 10 {
+		return fmt.Sprintf("%.1f %s", d, home.Units)
+	}
+	return fmt.Sprintf("%.0f %s", d, home.Units)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+// gitHubSource loads the open milestones with a due date, and optionally
+// the issues in them, of GitHub repositories as deadline events.
+type gitHubSource struct {
+	url    string
+	token  string
+	repos  []string
+	issues bool
+	f      *ical.Fetcher
+	tz     *time.Location
+}
+
+func newGitHubSource(cal Calendar, f *ical.Fetcher, tz *time.Location) (*gitHubSource, error) {
+	if len(cal.Repos) == 0 {
+		return nil, errors.New("github calendar repos are required")
+	}
+	for _, repo := range cal.Repos {
+		if owner, name, ok := strings.Cut(repo, "/"); !ok || owner == "" || name == "" {
+			return nil, fmt.Errorf("invalid github repo %q", repo)
+		}
+	}
+	return &gitHubSource{
+		url:    strings.TrimSuffix(cal.URL, "/"),
+		token:  cal.Auth.Token,
+		repos:  cal.Repos,
+		issues: cal.Issues,
+		f:      f,
+		tz:     tz,
+	}, nil
+}
+
+type gitHubMilestone struct {
+	Number      int        `json:"number"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	HTMLURL     string     `json:"html_url"`
+	DueOn       *time.Time `json:"due_on"`
+}
+
+type gitHubIssue struct {
+	Number      int              `json:"number"`
+	Title       string           `json:"title"`
+	HTMLURL     string           `json:"html_url"`
+	Milestone   *gitHubMilestone `json:"milestone"`
+	PullRequest *struct{}        `json:"pull_request"`
+}
+
+func (s *gitHubSource) events(ctx context.Context, start, end time.Time) ([]ical.Event, error) {
+	var evnts []ical.Event
+	for _, repo := range s.repos {
+		var milestones []gitHubMilestone
+		if err := s.get(ctx, "/repos/"+escapePath(repo)+"/milestones?state=open&per_page=100", &milestones); err != nil {
+			return nil, fmt.Errorf("fetching milestones of %q: %w", repo, err)
+		}
+		for _, ms := range milestones {
+			if ms.DueOn == nil {
+				continue
+			}
+			evnt, ok := allDayEvent(ms.DueOn.UTC(), s.tz, start, end)
+			if !ok {
+				continue
+			}
+			evnt.UID = "github-milestone-" + repo + "-" + strconv.Itoa(ms.Number) + "@glasslabs-calendar"
+			evnt.Summary = ms.Title
+			evnt.Location = repo
+			evnt.Description = strings.TrimSpace(ms.Description + "\n" + ms.HTMLURL)
+			evnts = append(evnts, evnt)
+		}
+
+		if !s.issues {
+			continue
+		}
+		var issues []gitHubIssue
+		if err := s.get(ctx, "/repos/"+escapePath(repo)+"/issues?state=open&milestone=*&per_page=100", &issues); err != nil {
+			return nil, fmt.Errorf("fetching issues of %q: %w", repo, err)
+		}
+		for _, issue := range issues {
+			if issue.PullRequest != nil || issue.Milestone == nil || issue.Milestone.DueOn == nil {
+				continue
+			}
+			evnt, ok := allDayEvent(issue.Milestone.DueOn.UTC(), s.tz, start, end)
+			if !ok {
+				continue
+			}
+			evnt.UID = "github-issue-" + repo + "-" + strconv.Itoa(issue.Number) + "@glasslabs-calendar"
+			evnt.Summary = "#" + strconv.Itoa(issue.Number) + " " + issue.Title
+			evnt.Location = repo
+			evnt.Description = issue.HTMLURL
+			evnts = append(evnts, evnt)
+		}
+	}
+	return evnts, nil
+}
+
+// get decodes the JSON response of the API path into v.
+func (s *gitHubSource) get(ctx context.Context, path string, v any) error {
+	header := http.Header{}
+	header.Set("Accept", "application/vnd.github+json")
+	if s.token != "" {
+		header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	b, err := s.f.Request(ctx, http.MethodGet, s.url+path, header, nil)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
+	}
+	return nil
+}
+
+// allDayEvent returns an all day event on the date of t in the timezone,
+// if the day overlaps the window between start and end.
+func allDayEvent(t time.Time, tz *time.Location, start, end time.Time) (ical.Event, bool) {
+	y, m, d := t.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, tz)
+	next := day.AddDate(0, 0, 1)
+	if !next.After(start) || !day.Before(end) {
+		return ical.Event{}, false
+	}
+	return ical.Event{Start: day, End: next, AllDay: true}, true
+}
+
+// escapePath escapes each segment of a slash separated path.
+func escapePath(p string) string {
+	segs := strings.Split(p, "/")
+	for i, seg := range segs {
+		segs[i] = url.PathEscape(seg)
+	}
+	return strings.Join(segs, "/")
+}
@@ -5,11 +5,11 @@ go 1.22
 require (
 	github.com/apognu/gocal v0.9.1
 	github.com/glasslabs/client-go v0.2.0
+	gopkg.in/yaml.v3 v3.0.1
+	honnef.co/go/js/dom/v2 v2.0.0-20231112215516-51f43a291193
 )
 
 require (
 	github.com/ChannelMeter/iso8601duration v0.0.0-20150204201828-8da3af7a2a61 // indirect
 	github.com/stretchr/testify v1.8.1 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
-	honnef.co/go/js/dom/v2 v2.0.0-20231112215516-51f43a291193 // indirect
 )
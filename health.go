@@ -0,0 +1,40 @@
+//go:build js && wasm
+
+package main
+
+import "time"
+
+// health returns the health of the module and the time of the oldest
+// successful fetch. Must be called with the lock held.
+func (m *Module) health(now time.Time) (string, time.Time) {
+	status := healthOK
+	var updated time.Time
+	for _, src := range m.sources {
+		switch src.status(now) {
+		case healthError:
+			status = healthError
+		case healthStale:
+			if status == healthOK {
+				status = healthStale
+			}
+		}
+		if updated.IsZero() || src.fetched.Before(updated) {
+			updated = src.fetched
+		}
+	}
+	return status, updated
+}
+
+// reportHealth sets the health of the module on its element so that
+// supervisors can probe it, e.g. with the selector `[data-health=ok]`.
+func (m *Module) reportHealth(now time.Time) {
+	m.mu.Lock()
+	status, updated := m.health(now)
+	m.mu.Unlock()
+
+	elem := m.mod.Element()
+	elem.SetAttribute("data-health", status)
+	if !updated.IsZero() {
+		elem.SetAttribute("data-updated", updated.UTC().Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// workingHours is a daily range of working hours.
+type workingHours struct {
+	start, end time.Duration
+}
+
+func newWorkingHours(cfg WorkingHours) (*workingHours, error) {
+	if cfg.Start == "" && cfg.End == "" {
+		return nil, nil
+	}
+
+	start, err := parseTimeOfDay(cfg.Start)
+	if err != nil {
+		return nil, fmt.Errorf("parsing working hours start: %w", err)
+	}
+	end, err := parseTimeOfDay(cfg.End)
+	if err != nil {
+		return nil, fmt.Errorf("parsing working hours end: %w", err)
+	}
+	if end <= start {
+		return nil, fmt.Errorf("working hours end %s must be after start %s", cfg.End, cfg.Start)
+	}
+	return &workingHours{start: start, end: end}, nil
+}
+
+// contains reports whether t is within working hours on its day.
+func (h *workingHours) contains(t time.Time) bool {
+	return !t.Before(atTimeOfDay(t, h.start)) && t.Before(atTimeOfDay(t, h.end))
+}
+
+// overlaps reports whether the range from start to end overlaps the
+// working hours of the day of start.
+func (h *workingHours) overlaps(start, end time.Time) bool {
+	if end.Before(start) {
+		end = start
+	}
+	dayStart, dayEnd := atTimeOfDay(start, h.start), atTimeOfDay(start, h.end)
+	if end.Equal(start) {
+		return h.contains(start)
+	}
+	return start.Before(dayEnd) && end.After(dayStart)
+}
+
+// Quiet hours modes.
+const (
+	quietBlank   = "blank"
+	quietMinimal = "minimal"
+)
+
+// quietHours is a daily range of time during which little or nothing
+// is rendered. The range may cross midnight.
+type quietHours struct {
+	start, end time.Duration
+	mode       string
+}
+
+func newQuietHours(cfg QuietHours) (*quietHours, error) {
+	if cfg.Start == "" && cfg.End == "" {
+		return nil, nil
+	}
+
+	start, err := parseTimeOfDay(cfg.Start)
+	if err != nil {
+		return nil, fmt.Errorf("parsing quiet hours start: %w", err)
+	}
+	end, err := parseTimeOfDay(cfg.End)
+	if err != nil {
+		return nil, fmt.Errorf("parsing quiet hours end: %w", err)
+	}
+	if end == start {
+		return nil, fmt.Errorf("quiet hours end %s must differ from start %s", cfg.End, cfg.Start)
+	}
+
+	mode := cfg.Mode
+	switch mode {
+	case "":
+		mode = quietBlank
+	case quietBlank, quietMinimal:
+	default:
+		return nil, fmt.Errorf("unknown quiet hours mode %q", cfg.Mode)
+	}
+	return &quietHours{start: start, end: end, mode: mode}, nil
+}
+
+// contains reports whether t is within quiet hours.
+func (h *quietHours) contains(t time.Time) bool {
+	afterStart, beforeEnd := !t.Before(atTimeOfDay(t, h.start)), t.Before(atTimeOfDay(t, h.end))
+	if h.start < h.end {
+		return afterStart && beforeEnd
+	}
+	return afterStart || beforeEnd
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+// iconFonts are the stylesheets of the supported icon fonts.
+var iconFonts = map[string]string{
+	"fontawesome": "https://cdn.jsdelivr.net/npm/@fortawesome/fontawesome-free@6.5.2/css/all.min.css",
+	"mdi":         "https://cdn.jsdelivr.net/npm/@mdi/font@7.4.47/css/materialdesignicons.min.css",
+}
+
+// iconRule sets the icon of events with a title matching its pattern.
+type iconRule struct {
+	re   *regexp.Regexp
+	icon string
+}
+
+func newIconRules(cfgs []Icon) ([]iconRule, error) {
+	rules := make([]iconRule, 0, len(cfgs))
+	for i, cfg := range cfgs {
+		re, err := regexp.Compile(cfg.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("icon %d: parsing pattern: %w", i, err)
+		}
+		rules = append(rules, iconRule{re: re, icon: cfg.Icon})
+	}
+	return rules, nil
+}
+
+// icon returns the icon classes of the event, from the first matching
+// keyword rule or else its calendar.
+func (p *pipeline) icon(evnt ical.Event) string {
+	for _, rule := range p.icons {
+		if rule.re.MatchString(evnt.Summary) {
+			return rule.icon
+		}
+	}
+	return p.calendarIcons[evnt.Calendar]
+}
@@ -0,0 +1,104 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+// injectedEvent is a transient event pushed by another module.
+type injectedEvent struct {
+	id      string
+	event   ical.Event
+	expires time.Time
+}
+
+// injectMessage is received on the "calendar.inject" topic.
+type injectMessage struct {
+	Module  string    `json:"module"`
+	ID      string    `json:"id"`
+	Title   string    `json:"title"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	AllDay  bool      `json:"allDay"`
+	Expires time.Time `json:"expires"`
+}
+
+// handleInject adds an event pushed by another module. An event with the
+// same id as a previously injected event replaces it.
+func (m *Module) handleInject(data []byte) {
+	var msg injectMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		m.log.Error("Could not parse inject message", "error", err.Error())
+		return
+	}
+	if msg.Module != "" && msg.Module != m.mod.Name() {
+		return
+	}
+	if msg.Title == "" || msg.Start.IsZero() {
+		m.log.Error("Could not inject event", "error", "title and start are required")
+		return
+	}
+
+	end := msg.End
+	if end.Before(msg.Start) {
+		end = msg.Start
+	}
+	expires := msg.Expires
+	if expires.IsZero() {
+		expires = end
+	}
+
+	inj := injectedEvent{
+		id: msg.ID,
+		event: ical.Event{
+			UID:     msg.ID,
+			Summary: msg.Title,
+			Start:   msg.Start,
+			End:     end,
+			AllDay:  msg.AllDay,
+		},
+		expires: expires,
+	}
+
+	m.mu.Lock()
+	m.injected = append(removeInjected(m.injected, msg.ID), inj)
+	m.updateEvents()
+	m.mu.Unlock()
+
+	m.render()
+}
+
+// removeInjected removes the injected event with the given id.
+func removeInjected(injected []injectedEvent, id string) []injectedEvent {
+	if id == "" {
+		return injected
+	}
+
+	res := injected[:0]
+	for _, inj := range injected {
+		if inj.id != id {
+			res = append(res, inj)
+		}
+	}
+	return res
+}
+
+// pruneInjected removes expired injected events, returning true if any
+// were removed. Must be called with the lock held.
+func (m *Module) pruneInjected(now time.Time) bool {
+	n := len(m.injected)
+
+	res := m.injected[:0]
+	for _, inj := range m.injected {
+		if inj.expires.After(now) {
+			res = append(res, inj)
+		}
+	}
+	m.injected = res
+
+	return len(m.injected) != n
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+// jiraSource loads the due dates of the issues matching a JQL query
+// from Jira Cloud as all day events.
+type jiraSource struct {
+	url      string
+	username string
+	token    string
+	query    string
+	f        *ical.Fetcher
+	tz       *time.Location
+}
+
+func newJiraSource(cal Calendar, f *ical.Fetcher, tz *time.Location) (*jiraSource, error) {
+	if cal.URL == "" {
+		return nil, errors.New("jira calendar url is required")
+	}
+	if cal.Auth.Username == "" || cal.Auth.Password == "" {
+		return nil, errors.New("jira calendar username and api token are required")
+	}
+	if cal.Query == "" {
+		return nil, errors.New("jira calendar query is required")
+	}
+	return &jiraSource{
+		url:      strings.TrimSuffix(cal.URL, "/"),
+		username: cal.Auth.Username,
+		token:    cal.Auth.Password,
+		query:    cal.Query,
+		f:        f,
+		tz:       tz,
+	}, nil
+}
+
+func (s *jiraSource) events(ctx context.Context, start, end time.Time) ([]ical.Event, error) {
+	q := url.Values{}
+	q.Set("jql", s.query)
+	q.Set("fields", "summary,duedate")
+	q.Set("maxResults", "100")
+
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+	header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(s.username+":"+s.token)))
+
+	b, err := s.f.Request(ctx, http.MethodGet, s.url+"/rest/api/3/search/jql?"+q.Encode(), header, nil)
+	if err != nil {
+		return nil, fmt.Errorf("searching issues: %w", err)
+	}
+	var resp struct {
+		Issues []struct {
+			Key    string `json:"key"`
+			Fields struct {
+				Summary string `json:"summary"`
+				DueDate string `json:"duedate"`
+			} `json:"fields"`
+		} `json:"issues"`
+	}
+	if err = json.Unmarshal(b, &resp); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+
+	var evnts []ical.Event
+	for _, issue := range resp.Issues {
+		if issue.Fields.DueDate == "" {
+			continue
+		}
+		due, err := time.Parse(time.DateOnly, issue.Fields.DueDate)
+		if err != nil {
+			return nil, fmt.Errorf("parsing due date of %s: %w", issue.Key, err)
+		}
+		evnt, ok := allDayEvent(due, s.tz, start, end)
+		if !ok {
+			continue
+		}
+		evnt.UID = "jira-" + issue.Key + "@glasslabs-calendar"
+		evnt.Summary = issue.Key + " " + issue.Fields.Summary
+		evnt.Description = s.url + "/browse/" + issue.Key
+		evnt.Categories = []string{issue.Key}
+		evnts = append(evnts, evnt)
+	}
+	return evnts, nil
+}
@@ -1,62 +1,28 @@
+//go:build js && wasm
+
 package main
 
 import (
-	"bytes"
-	_ "embed"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"html/template"
-	"io"
-	"net/http"
-	"sort"
+	"net/url"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"sync"
+	"syscall"
 	"time"
 	_ "time/tzdata"
 
-	"github.com/apognu/gocal"
+	"github.com/glasslabs/calendar/pkg/ical"
+	"github.com/glasslabs/calendar/pkg/render"
 	"github.com/glasslabs/client-go"
+	"honnef.co/go/js/dom/v2"
 )
 
-var (
-	//go:embed assets/style.css
-	css []byte
-
-	//go:embed assets/index.html
-	html []byte
-)
-
-// Event contains event information.
-type Event struct {
-	Title    string
-	Time     time.Time
-	IsAllDay bool
-	IsToday  bool
-}
-
-// Config is the module configuration.
-type Config struct {
-	Timezone  string     `yaml:"timezone"`
-	Calendars []Calendar `yaml:"calendars"`
-
-	MaxDays   int `yaml:"maxDays"`
-	MaxEvents int `yaml:"maxEvents"`
-
-	Interval time.Duration `yaml:"interval"`
-}
-
-// Calendar is a calendar configuration.
-type Calendar struct {
-	URL       string `yaml:"url"`
-	MaxEvents int    `yaml:"maxEvents"`
-}
-
-// NewConfig creates a default configuration for the module.
-func NewConfig() Config {
-	return Config{
-		MaxDays:   5,
-		MaxEvents: 20,
-		Interval:  30 * time.Minute,
-	}
-}
-
 func main() {
 	log := client.NewLogger()
 	mod, err := client.NewModule()
@@ -70,13 +36,35 @@ func main() {
 		log.Error("Could not parse config", "error", err.Error())
 		return
 	}
+	cfg.removeDisabled()
+	if err = cfg.checkHostOnly(); err != nil {
+		log.Error("Could not parse config", "error", err.Error())
+		return
+	}
+	if err = cfg.Validate(); err != nil {
+		log.Error("Invalid config", "error", err.Error())
+		return
+	}
+	tz := cfg.Timezone
+	if err = cfg.detectTimezone(context.Background()); err != nil {
+		log.Error("Could not detect timezone", "error", err.Error())
+	} else if cfg.Timezone != tz {
+		log.Info("Detected timezone", "timezone", cfg.Timezone)
+	}
 
 	log.Info("Loading Module", "module", mod.Name())
 
+	clock, err := newClock(cfg.Fixture)
+	if err != nil {
+		log.Error("Could not parse config", "error", err.Error())
+		return
+	}
+
 	m := &Module{
-		mod: mod,
-		cfg: cfg,
-		log: log,
+		mod:   mod,
+		cfg:   cfg,
+		clock: clock,
+		log:   log,
 	}
 
 	if err = m.setup(); err != nil {
@@ -84,170 +72,752 @@ func main() {
 		return
 	}
 
-	m.load()
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	// Stop when the page is unloaded.
+	dom.GetWindow().AddEventListener("pagehide", false, func(dom.Event) { cancel() })
+
+	for _, src := range m.sources {
+		m.load(ctx, src)
+	}
 	m.render()
+	if m.alarms != nil || m.chimes != nil {
+		m.checkReminders()
+	}
+
+	var wg sync.WaitGroup
+	for _, src := range m.sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			m.schedule(ctx, src)
+		}()
+	}
+
+	if len(m.views) > 1 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			m.rotate(ctx)
+		}()
+	}
+
+	if m.travel != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
 
-	evntTicker := time.NewTicker(cfg.Interval)
-	defer evntTicker.Stop()
+			m.planTravel(ctx)
+		}()
+	}
+
+	if m.busy != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			m.pollFreeBusy(ctx)
+		}()
+	}
+
+	if len(cfg.Notify) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			m.notify(ctx)
+		}()
+	}
+
+	if cfg.Debug {
+		m.exposeDebug()
+	}
+
+	m.subscribe("calendar.refresh", func(data []byte) {
+		m.handleRefresh(ctx, data)
+	})
+	m.subscribe("calendar.inject", m.handleInject)
+	m.subscribe("calendar.dismiss", m.handleDismiss)
+	m.subscribe("calendar.profile", m.handleProfile)
+	m.subscribe("calendar.add", func(data []byte) {
+		m.handleAdd(ctx, data)
+	})
+	m.subscribe("calendar.complete", func(data []byte) {
+		m.handleComplete(ctx, data)
+	})
 
 	rndrTicker := time.NewTicker(time.Minute)
 	defer rndrTicker.Stop()
 
 	for {
 		select {
-		case <-evntTicker.C:
-			m.load()
+		case <-ctx.Done():
+			log.Info("Stopping module", "module", mod.Name())
+			wg.Wait()
+			if m.mqtt != nil {
+				_ = m.mqtt.Close()
+			}
+			return
 		case <-rndrTicker.C:
 			m.render()
+			if m.alarms != nil || m.chimes != nil {
+				m.checkReminders()
+			}
 		}
 	}
 }
 
 // Module is a calendar module.
 type Module struct {
-	mod *client.Module
-	cfg Config
-
-	tmpl *template.Template
-	tz   *time.Location
-
-	events []Event
+	mod   *client.Module
+	cfg   Config
+	clock Clock
+
+	renderer render.Renderer
+	pipe     *pipeline
+	views    []string
+
+	fetcher *ical.Fetcher
+	sources []*source
+	mqtt    *mqttPublisher
+	travel  *travelPlanner
+	busy    *freeBusyClient
+	store   kvStore
+	prefs   kvStore
+
+	mu          sync.Mutex
+	discovered  bool
+	expanded    string
+	collapse    *time.Timer
+	alarms      *alarms
+	chimes      *chimes
+	remindTimer *time.Timer
+	rendered    string
+	viewIdx     int
+	injected    []injectedEvent
+	dismissed   map[string]time.Time
+	profile     string
+	active      *profile
+	history     []change
+	events      []render.Event
+	days        dayIndex
+	more        int
+
+	// merged is the backing array of the events merged from all
+	// sources, reused by each merge as they are converted for display.
+	merged []ical.Event
 
 	log *client.Logger
 }
 
 func (m *Module) setup() error {
-	tmpl, err := template.New("html").Parse(string(html))
+	renderer, err := render.New()
+	if err != nil {
+		return err
+	}
+	if m.cfg.EventTemplate != "" {
+		if err = renderer.Override("event", m.cfg.EventTemplate); err != nil {
+			return err
+		}
+	}
+	m.renderer = renderer
+
+	m.views = m.cfg.Views
+	if len(m.views) == 0 {
+		m.views = []string{m.cfg.View}
+	}
+
+	if m.cfg.Alarms.Show || m.cfg.Alarms.Publish {
+		m.alarms = newAlarms(m.cfg.Alarms.Timeout)
+	}
+	if m.cfg.chimesEnabled() {
+		m.chimes = newChimes()
+	}
+
+	m.pipe, err = newPipeline(m.cfg)
 	if err != nil {
-		return fmt.Errorf("parsing html: %w", err)
+		return err
 	}
-	m.tmpl = tmpl
 
-	//nolint:gosmopolitan
-	m.tz = time.Local
-	if m.cfg.Timezone != "" {
-		tz, err := time.LoadLocation(m.cfg.Timezone)
+	m.fetcher = newFetcher(m.cfg)
+	m.sources, err = newSources(m.cfg, m.fetcher)
+	if err != nil {
+		return err
+	}
+
+	if m.cfg.Travel.Provider != "" {
+		m.travel, err = newTravelPlanner(m.cfg.Travel, m.fetcher)
 		if err != nil {
-			return fmt.Errorf("parsing timezone: %w", err)
+			return err
 		}
-		m.tz = tz
 	}
 
-	if err = m.mod.LoadCSS(string(css)); err != nil {
+	if len(m.cfg.FreeBusy.Calendars) > 0 {
+		m.busy, err = newFreeBusyClient(m.cfg.FreeBusy, m.fetcher)
+		if err != nil {
+			return err
+		}
+	}
+
+	if m.cfg.SharedCache {
+		for _, src := range m.sources {
+			src.cache = windowCache{}
+		}
+	}
+
+	m.prefs = localStorage{prefix: "glasslabs-calendar/" + m.mod.Name() + "/"}
+	m.dismissed = map[string]time.Time{}
+	loadJSON(m.prefs, "dismissed", &m.dismissed)
+	if loadJSON(m.prefs, "profile", &m.profile) && m.pipe.profile(m.profile, time.Time{}) == nil {
+		m.profile = ""
+	}
+
+	if m.cfg.Store {
+		m.store = localStorage{prefix: "glasslabs-calendar/" + m.mod.Name() + "/"}
+		m.restore()
+	}
+
+	if m.cfg.MQTT.URL != "" {
+		mqttCfg := m.cfg.MQTT
+		if mqttCfg.ClientID == "" {
+			mqttCfg.ClientID = "glasslabs-calendar-" + m.mod.Name()
+		}
+		m.mqtt = &mqttPublisher{cfg: mqttCfg, dial: dialWebSocket}
+	}
+
+	styles, err := m.styles()
+	if err != nil {
+		return err
+	}
+	if err = m.mod.LoadCSS(styles...); err != nil {
 		return fmt.Errorf("loading css: %w", err)
 	}
+	if m.cfg.IconFont != "" {
+		if err = loadIconFont(m.cfg.IconFont); err != nil {
+			return err
+		}
+	}
+	if err = m.applyScale(); err != nil {
+		return err
+	}
+
+	m.mod.Element().AddEventListener("click", false, m.handleClick)
 	return nil
 }
 
-func (m *Module) load() {
-	events, err := m.loadEvents()
-	if err != nil {
-		m.log.Error("Could not load events", "error", err.Error())
+// styles returns the embedded styles and theme followed by the configured
+// custom styles.
+func (m *Module) styles() ([]string, error) {
+	styles := []string{string(css)}
+
+	if m.cfg.Theme != "" {
+		b, err := themes.ReadFile("assets/themes/" + m.cfg.Theme + ".css")
+		if err != nil {
+			return nil, fmt.Errorf("unknown theme %q", m.cfg.Theme)
+		}
+		styles = append(styles, string(b))
+	}
+
+	if m.cfg.CSSURL != "" {
+		var (
+			b   []byte
+			err error
+		)
+		if u, _ := url.Parse(m.cfg.CSSURL); u != nil && u.IsAbs() {
+			ctx, cancel := context.WithTimeout(context.Background(), m.cfg.Timeout)
+			b, err = m.fetcher.Fetch(ctx, m.cfg.CSSURL)
+			cancel()
+		} else {
+			b, err = m.mod.Asset(m.cfg.CSSURL)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("loading css %q: %w", m.cfg.CSSURL, err)
+		}
+		styles = append(styles, string(b))
+	}
+	if m.cfg.CSS != "" {
+		styles = append(styles, m.cfg.CSS)
+	}
+	return styles, nil
+}
+
+// loadIconFont adds the stylesheet of the icon font to the page, once
+// for all modules. The font is either a supported icon font or the URL
+// of its stylesheet.
+func loadIconFont(font string) error {
+	href, ok := iconFonts[font]
+	if !ok {
+		if u, err := url.Parse(font); err != nil || !u.IsAbs() {
+			return fmt.Errorf("unknown icon font %q", font)
+		}
+		href = font
+	}
+
+	doc := dom.GetWindow().Document()
+	for _, link := range doc.QuerySelectorAll(`link[rel="stylesheet"]`) {
+		if link.GetAttribute("href") == href {
+			return nil
+		}
+	}
+
+	head := doc.QuerySelector("head")
+	if head == nil {
+		return errors.New("head element not found")
+	}
+	link := doc.CreateElement("link")
+	link.SetAttribute("rel", "stylesheet")
+	link.SetAttribute("href", href)
+	head.AppendChild(link)
+	return nil
+}
+
+// applyScale sets the font scaling of the module as CSS variables
+// on its element.
+func (m *Module) applyScale() error {
+	if m.cfg.Scale <= 0 {
+		return fmt.Errorf("invalid scale %v", m.cfg.Scale)
+	}
+
+	elem, ok := m.mod.Element().(dom.HTMLElement)
+	if !ok {
+		return nil
+	}
+	style := elem.Style()
+	style.SetProperty("--calendar-scale", strconv.FormatFloat(m.cfg.Scale, 'f', -1, 64), "")
+	if m.cfg.FontSize != "" {
+		style.SetProperty("--calendar-font-size", m.cfg.FontSize, "")
+	}
+	return nil
+}
+
+// setVisible shows or hides the module element, so other modules
+// may use its space while it is hidden.
+func (m *Module) setVisible(visible bool) {
+	elem, ok := m.mod.Element().(dom.HTMLElement)
+	if !ok {
+		return
+	}
+	if visible {
+		elem.Style().RemoveProperty("display")
 		return
 	}
-	m.events = events
+	elem.Style().SetProperty("display", "none", "")
 }
 
-func (m *Module) render() {
-	var buf bytes.Buffer
-	if err := m.tmpl.Execute(&buf, map[string]interface{}{"Events": m.events}); err != nil {
-		m.log.Error("Could not render HTML", "error", err.Error())
+// handleRefresh reloads all sources immediately. A module name
+// may be given to target a single calendar module.
+func (m *Module) handleRefresh(ctx context.Context, data []byte) {
+	var msg struct {
+		Module string `json:"module"`
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &msg); err != nil {
+			m.log.Error("Could not parse refresh message", "error", err.Error())
+			return
+		}
+	}
+	if msg.Module != "" && msg.Module != m.mod.Name() {
 		return
 	}
-	m.mod.Element().SetInnerHTML(buf.String())
+
+	for _, src := range m.sources {
+		if src.cache != nil {
+			src.cache.remove(src.cal.URL)
+		}
+		m.load(ctx, src)
+	}
+	m.render()
 }
 
-func (m *Module) loadEvents() ([]Event, error) {
-	start := time.Now()
-	end := time.Now().Add(time.Duration(m.cfg.MaxDays) * 24 * time.Hour)
+// notify posts webhook notifications for upcoming events every minute.
+func (m *Module) notify(ctx context.Context) {
+	n := newNotifier(m.mod.Name(), m.fetcher.Client, m.cfg.Notify)
 
-	m.log.Info("Fetching events data", "module", "calendar", "id", m.mod.Name())
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
 
-	var evnts []gocal.Event
-	for _, cal := range m.cfg.Calendars {
-		e, err := loadCalendar(cal.URL, cal.MaxEvents, start, end)
-		if err != nil {
-			return nil, err
+	for {
+		m.mu.Lock()
+		events := m.events
+		m.mu.Unlock()
+
+		for _, err := range n.check(ctx, m.clock.Now(), events) {
+			m.log.Error("Could not send notification", "error", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
 		}
-		evnts = append(evnts, e...)
 	}
+}
 
-	sort.Slice(evnts, func(i, j int) bool {
-		return evnts[i].Start.Before(*evnts[j].Start)
-	})
-	if m.cfg.MaxEvents > 0 && len(evnts) > m.cfg.MaxEvents {
-		evnts = evnts[:m.cfg.MaxEvents]
+// rotate cycles through the views on the rotate interval.
+func (m *Module) rotate(ctx context.Context) {
+	ticker := time.NewTicker(m.cfg.RotateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.mu.Lock()
+			m.viewIdx = (m.viewIdx + 1) % len(m.views)
+			m.mu.Unlock()
+
+			m.render()
+		}
 	}
+}
 
-	events := make([]Event, 0, len(evnts))
-	for _, evnt := range evnts {
-		events = append(events, Event{
-			Title:    evnt.Summary,
-			Time:     evnt.Start.In(m.tz),
-			IsAllDay: isAllDayEvent(evnt),
-			IsToday:  isToday(evnt.Start),
-		})
+// planTravel updates the travel times of upcoming events every minute.
+func (m *Module) planTravel(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		m.mu.Lock()
+		events := m.events
+		m.mu.Unlock()
+
+		for _, err := range m.travel.update(ctx, m.clock.Now(), events) {
+			m.log.Error("Could not get travel time", "error", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollFreeBusy queries the combined availability of the free busy
+// calendars on the module interval.
+func (m *Module) pollFreeBusy(ctx context.Context) {
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.busy.update(ctx, m.clock.Now()); err != nil {
+			m.log.Error("Could not query free busy", "error", err.Error())
+		}
+		m.render()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// restore loads the persisted events of all sources and the change history,
+// so events are available before the first fetch.
+func (m *Module) restore() {
+	for _, src := range m.sources {
+		var snap snapshot
+		if loadJSON(m.store, "source/"+src.cal.URL, &snap) {
+			src.fetched = snap.Fetched
+			src.title = snap.Title
+			src.cancelled = snap.Cancelled
+			src.events = snap.Events
+		}
+	}
+	loadJSON(m.store, "history", &m.history)
+	m.updateEvents()
+}
+
+// persist stores the fetched events of the source, recording changes since
+// the previous fetch. Must be called with the lock held.
+func (m *Module) persist(src *source, snap snapshot) {
+	if !src.fetched.IsZero() {
+		changes := diffEvents(src.name(), snap.Fetched, src.events, snap.Events)
+		for _, c := range changes {
+			if c.Type == "added" {
+				m.log.Info("New event added", "calendar", c.Calendar, "title", c.Title)
+			}
+		}
+		if len(changes) > 0 {
+			m.history = appendHistory(m.history, changes)
+			if err := saveJSON(m.store, "history", m.history); err != nil {
+				m.log.Error("Could not store history", "error", err.Error())
+			}
+		}
+	}
+
+	if err := saveJSON(m.store, "source/"+src.cal.URL, snap); err != nil {
+		m.log.Error("Could not store events", "url", src.cal.URL, "error", err.Error())
+	}
+}
+
+// schedule reloads the source on its interval.
+func (m *Module) schedule(ctx context.Context, src *source) {
+	timer := time.NewTimer(m.nextInterval(src))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			m.load(ctx, src)
+			timer.Reset(m.nextInterval(src))
+		}
+	}
+}
+
+// nextInterval returns the time until the next fetch of the source,
+// doubling the interval for each failure once backing off.
+func (m *Module) nextInterval(src *source) time.Duration {
+	m.mu.Lock()
+	failures := src.failures
+	m.mu.Unlock()
+
+	if m.cfg.BackoffAfter <= 0 || failures < m.cfg.BackoffAfter {
+		return src.interval
+	}
+
+	maxBackoff := max(m.cfg.MaxBackoff, src.interval)
+	d := src.interval
+	for i := m.cfg.BackoffAfter; i <= failures && d < maxBackoff; i++ {
+		d *= 2
 	}
-	return events, nil
+	return min(d, maxBackoff)
 }
 
-func loadCalendar(url string, maxEvents int, start, end time.Time) ([]gocal.Event, error) {
-	//nolint:noctx
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+func (m *Module) load(ctx context.Context, src *source) {
+	start := m.clock.Now()
+	end := m.pipe.windowEnd(start)
+
+	m.log.Info("Fetching events data", "module", "calendar", "id", m.mod.Name(), "url", src.cal.URL)
+
+	cal, err := src.load(ctx, m.fetcher, start, end)
+	if ctx.Err() != nil {
+		return
+	}
+
+	m.mu.Lock()
+	ok := m.updateSource(src, start, end, cal, err)
+	events := m.events
+	m.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	if m.alarms != nil || m.chimes != nil {
+		m.checkReminders()
+	}
+
+	msg := m.summarize(events, start)
+	m.broadcast(msg, start)
+	if m.mqtt != nil {
+		m.publishMQTT(ctx, msg)
+	}
+}
+
+// updateSource records the result of a fetch of the source within the
+// window ending at end, returning true if it succeeded. Must be called
+// with the lock held.
+func (m *Module) updateSource(src *source, fetched, end time.Time, cal *ical.Calendar, err error) bool {
 	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+		m.log.Error("Could not load events", "url", src.cal.URL, "error", err.Error())
+
+		src.err = err
+		src.failures++
+		if src.failures == m.cfg.BackoffAfter {
+			m.log.Info("Backing off calendar fetches", "url", src.cal.URL, "failures", strconv.Itoa(src.failures))
+		}
+		return false
+	}
+
+	if m.cfg.BackoffAfter > 0 && src.failures >= m.cfg.BackoffAfter {
+		m.log.Info("Calendar fetches recovered", "url", src.cal.URL)
 	}
+	src.cancel(cal.Cancelled, fetched, end)
+	evnts := src.suppress(cal.Events)
+	if m.store != nil {
+		m.persist(src, snapshot{Fetched: fetched, Title: cal.Name, Events: evnts, Cancelled: src.cancelled})
+	}
+	src.title = cal.Name
+	src.fetched = fetched
+	src.err = nil
+	src.failures = 0
+	src.events = evnts
+	m.updateEvents()
+	return true
+}
+
+func (m *Module) render() {
+	now := m.clock.Now()
+	model := m.viewModel(now)
 
-	resp, err := http.DefaultClient.Do(req)
+	out, err := m.renderer.Render(model)
 	if err != nil {
-		return nil, fmt.Errorf("requesting calendar %q: %w", url, err)
+		m.log.Error("Could not render HTML", "error", err.Error())
+		return
 	}
-	defer func() {
-		_, _ = io.Copy(io.Discard, resp.Body)
-		_ = resp.Body.Close()
-	}()
 
-	if resp.StatusCode != http.StatusOK {
-		b, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("fetching calendar %q: %d %s", url, resp.StatusCode, string(b))
+	// Unchanged content is not replaced and changed content is patched
+	// to avoid needless repaints, which are slow and visible on e-paper
+	// displays.
+	m.mu.Lock()
+	changed := out != m.rendered
+	m.rendered = out
+	m.mu.Unlock()
+	if changed {
+		patch(m.mod.Element(), out)
+	}
+	if m.cfg.HideIfEmptyWithin > 0 {
+		m.setVisible(hasUpcoming(model.Events, now, m.cfg.HideIfEmptyWithin))
 	}
 
-	gcal := gocal.NewParser(resp.Body)
-	gcal.Start = &start
-	gcal.End = &end
-	if err = gcal.Parse(); err != nil {
-		return nil, fmt.Errorf("parsing calendar %q: %w", url, err)
+	m.reportHealth(now)
+}
+
+// viewModel builds the view model of the module at now.
+func (m *Module) viewModel(now time.Time) render.Model {
+	m.mu.Lock()
+	pruned := m.pruneInjected(now)
+	if m.pruneDismissed(now) || pruned || m.pipe.profile(m.profile, now) != m.active {
+		m.updateEvents()
+	}
+	events := make([]render.Event, len(m.events))
+	copy(events, m.events)
+	days := m.days
+	more := m.more
+	errs := m.fetchErrors()
+	status := m.sourceStatus(now)
+	expanded := m.expanded
+	view := m.views[m.viewIdx]
+	pinned := m.active != nil && m.active.view != ""
+	if pinned {
+		view = m.active.view
+	}
+	var pages []bool
+	if len(m.views) > 1 && m.cfg.PageIndicator != pageNone && !pinned {
+		pages = make([]bool, len(m.views))
+		pages[m.viewIdx] = true
 	}
+	page := m.viewIdx + 1
+	var reminders []render.Reminder
+	if m.alarms != nil && m.cfg.Alarms.Show {
+		reminders = append(reminders, m.alarms.active...)
+	}
+	m.mu.Unlock()
 
-	e := gcal.Events
-	if maxEvents > 0 && len(gcal.Events) > maxEvents {
-		e = e[:maxEvents]
+	if all := m.pipe.withAnniversaries(events, now); len(all) != len(events) {
+		// Anniversaries depend on the day, so events including
+		// them are indexed on each render.
+		events, days = all, newDayIndex(all)
+	}
+	for i := range events {
+		events[i].IsExpanded = expanded != "" && events[i].ID == expanded
+		if m.travel != nil {
+			if leaveBy, ok := m.travel.leaveBy(events[i]); ok && events[i].Time.After(now) {
+				events[i].LeaveBy = leaveBy
+				events[i].LeaveSoon = !now.Before(leaveBy.Add(-m.cfg.Travel.WarnBefore))
+			}
+		}
 	}
-	return e, nil
+
+	model := m.pipe.viewModel(view, events, days, now)
+	if m.busy != nil && model.Quiet == "" {
+		model.FreeBusy = m.busy.strip(now.In(m.pipe.tz))
+	}
+	model.More = more
+	model.Errors = errs
+	model.Status = status
+	model.Reminders = reminders
+	model.Pages = pages
+	model.Page = page
+	return model
 }
 
-func isAllDayEvent(evnt gocal.Event) bool {
-	if evnt.RawStart.Params["VALUE"] == "DATE" {
-		return true
+// fetchErrors returns the errors of all sources when every source
+// failed its last fetch.
+func (m *Module) fetchErrors() []render.FetchError {
+	if !m.cfg.ShowErrors || len(m.sources) == 0 {
+		return nil
 	}
 
-	var s time.Time
-	if evnt.Start != nil {
-		s = *evnt.Start
+	errs := make([]render.FetchError, 0, len(m.sources))
+	for _, src := range m.sources {
+		if src.err == nil {
+			return nil
+		}
+		errs = append(errs, render.FetchError{
+			Calendar: src.name(),
+			Class:    errorClass(src.err),
+		})
 	}
+	return errs
+}
 
-	var e time.Time
-	if evnt.Start != nil {
-		e = *evnt.End
+// sourceStatus returns the health of each source when enabled.
+// Must be called with the lock held.
+func (m *Module) sourceStatus(now time.Time) []render.SourceStatus {
+	if !m.cfg.ShowStatus {
+		return nil
 	}
 
-	return e.Sub(s) == 24*time.Hour && s.Hour() == 0 && s.Minute() == 0
+	status := make([]render.SourceStatus, 0, len(m.sources))
+	for _, src := range m.sources {
+		status = append(status, render.SourceStatus{
+			Calendar: src.name(),
+			Status:   src.status(now),
+		})
+	}
+	return status
 }
 
-func isToday(t *time.Time) bool {
-	if t == nil {
-		return false
+// updateEvents merges the events of all sources and indexes them by day,
+// so the index is built when the events change rather than on every
+// render. Must be called with the lock held.
+func (m *Module) updateEvents() {
+	m.events, m.more = m.mergeEvents()
+	m.days = newDayIndex(m.events)
+}
+
+// mergeEvents merges the events of all sources, returning the number
+// of events not shown due to the limit. Must be called with the lock held.
+func (m *Module) mergeEvents() ([]render.Event, int) {
+	n := len(m.injected)
+	for _, src := range m.sources {
+		n += len(src.events)
+	}
+	evnts := slices.Grow(m.merged[:0], n)
+	for _, src := range m.sources {
+		evnts = append(evnts, src.events...)
+	}
+	for _, inj := range m.injected {
+		evnts = append(evnts, inj.event)
+	}
+
+	start := m.clock.Now()
+	end := m.pipe.windowEnd(start)
+	evnts = append(evnts, m.pipe.generate(start, end)...)
+
+	if len(m.dismissed) > 0 {
+		res := evnts[:0]
+		for _, evnt := range evnts {
+			if !m.isDismissed(eventID(evnt), start) {
+				res = append(res, evnt)
+			}
+		}
+		evnts = res
+	}
+
+	m.active = m.pipe.profile(m.profile, start)
+	if m.active != nil {
+		evnts = m.active.filter(evnts)
 	}
 
-	return t.Truncate(24 * time.Hour).Equal(time.Now().UTC().Truncate(24 * time.Hour))
+	m.merged = evnts[:0]
+	return m.pipe.mergeEvents(evnts)
 }
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+	"github.com/glasslabs/calendar/pkg/render"
+)
+
+// presetMedia is the preset of media manager calendars, such as
+// those of Sonarr and Radarr.
+const presetMedia = "media"
+
+// mediaIcons are the TV icons of the supported icon fonts.
+var mediaIcons = map[string]string{
+	"fontawesome": "fa-solid fa-tv",
+	"mdi":         "mdi mdi-television-classic",
+}
+
+// episodeRE matches episode titles such as "Series - S01E05 - Title"
+// and "Series - 1x05 - Title".
+var episodeRE = regexp.MustCompile(`^(.+?)\s+-\s+(?:[Ss](\d+)[Ee](\d+)|(\d+)x(\d+))(?:\s+-\s+(.+))?$`)
+
+// episode is a TV episode parsed from an event title.
+type episode struct {
+	series string
+	season int
+	number int
+	title  string
+}
+
+// parseEpisode parses the series, season and episode numbers from
+// the title of a media manager event.
+func parseEpisode(s string) (episode, bool) {
+	m := episodeRE.FindStringSubmatch(s)
+	if m == nil {
+		return episode{}, false
+	}
+
+	season, number := m[2], m[3]
+	if season == "" {
+		season, number = m[4], m[5]
+	}
+	ep := episode{series: m[1], title: m[6]}
+	ep.season, _ = strconv.Atoi(season)
+	ep.number, _ = strconv.Atoi(number)
+	return ep, true
+}
+
+// applyEpisode shows the event as a TV episode when its title is one.
+func applyEpisode(event *render.Event, evnt ical.Event) {
+	ep, ok := parseEpisode(evnt.Summary)
+	if !ok {
+		return
+	}
+	event.Title = ep.series
+	event.Season = ep.season
+	event.Episode = ep.number
+	event.EpisodeTitle = ep.title
+}
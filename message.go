@@ -0,0 +1,121 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"syscall/js"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/render"
+	"honnef.co/go/js/dom/v2"
+)
+
+// Messages are exchanged with other modules as custom DOM events
+// dispatched on the window. The event detail, when present, is a
+// JSON encoded string.
+
+// subscribe calls fn with the detail of every message on the given topic.
+func (m *Module) subscribe(topic string, fn func(data []byte)) {
+	dom.GetWindow().AddEventListener(topic, false, func(evnt dom.Event) {
+		var data []byte
+		if detail := evnt.Underlying().Get("detail"); detail.Type() == js.TypeString {
+			data = []byte(detail.String())
+		}
+
+		// Handlers may block, which is not allowed in a JS callback.
+		go fn(data)
+	})
+}
+
+// publish dispatches a message on the given topic with v as its detail.
+func (m *Module) publish(topic string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encoding message: %w", err)
+	}
+
+	opts := map[string]any{"detail": string(b)}
+	js.Global().Call("dispatchEvent", js.Global().Get("CustomEvent").New(topic, opts))
+	return nil
+}
+
+// eventsMessage is published on the "calendar.events" topic after events are loaded.
+type eventsMessage struct {
+	Module     string         `json:"module"`
+	Next       *messageEvent  `json:"next,omitempty"`
+	TodayCount int            `json:"todayCount"`
+	Today      []messageEvent `json:"today"`
+	Events     []messageEvent `json:"events"`
+}
+
+// messageEvent is an event in a message.
+type messageEvent struct {
+	ID       string    `json:"id"`
+	Title    string    `json:"title"`
+	Location string    `json:"location,omitempty"`
+	Start    time.Time `json:"start"`
+	AllDay   bool      `json:"allDay"`
+}
+
+// summarize returns the upcoming events as a message.
+func (m *Module) summarize(events []render.Event, now time.Time) eventsMessage {
+	msg := eventsMessage{
+		Module: m.mod.Name(),
+		Today:  []messageEvent{},
+		Events: make([]messageEvent, 0, len(events)),
+	}
+	for _, evnt := range events {
+		me := messageEvent{
+			ID:       evnt.ID,
+			Title:    evnt.Title,
+			Location: evnt.Location,
+			Start:    evnt.Time,
+			AllDay:   evnt.IsAllDay,
+		}
+		msg.Events = append(msg.Events, me)
+
+		if isToday(evnt.Time, now) {
+			msg.TodayCount++
+			msg.Today = append(msg.Today, me)
+		}
+		if msg.Next == nil && !evnt.IsAllDay && evnt.Time.After(now) {
+			msg.Next = &me
+		}
+	}
+	return msg
+}
+
+// nextEventMessage is published on the next event topic after events
+// are loaded. The event fields are empty when there is no next event.
+type nextEventMessage struct {
+	Module   string     `json:"module"`
+	ID       string     `json:"id,omitempty"`
+	Title    string     `json:"title,omitempty"`
+	Location string     `json:"location,omitempty"`
+	Start    *time.Time `json:"start,omitempty"`
+	LeadTime string     `json:"leadTime,omitempty"`
+}
+
+// broadcast publishes the upcoming events for other modules.
+func (m *Module) broadcast(msg eventsMessage, now time.Time) {
+	if err := m.publish("calendar.events", msg); err != nil {
+		m.log.Error("Could not publish events", "error", err.Error())
+	}
+
+	if m.cfg.NextEvent.Topic == "" {
+		return
+	}
+	next := nextEventMessage{Module: msg.Module}
+	if msg.Next != nil {
+		next.ID = msg.Next.ID
+		next.Title = msg.Next.Title
+		next.Location = msg.Next.Location
+		next.Start = &msg.Next.Start
+		next.LeadTime = msg.Next.Start.Sub(now).Round(time.Second).String()
+	}
+	if err := m.publish(m.cfg.NextEvent.Topic, next); err != nil {
+		m.log.Error("Could not publish next event", "error", err.Error())
+	}
+}
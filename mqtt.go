@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// mqttConnect returns an MQTT 3.1.1 CONNECT packet with a clean session.
+func mqttConnect(clientID, username, password string, keepAlive time.Duration) []byte {
+	var body bytes.Buffer
+	writeMQTTString(&body, "MQTT")
+	body.WriteByte(4) // Protocol level 3.1.1.
+
+	flags := byte(0x02) // Clean session.
+	if username != "" {
+		flags |= 0x80
+	}
+	if password != "" {
+		flags |= 0x40
+	}
+	body.WriteByte(flags)
+	_ = binary.Write(&body, binary.BigEndian, uint16(keepAlive/time.Second)) //nolint:gosec // Validated to fit.
+
+	writeMQTTString(&body, clientID)
+	if username != "" {
+		writeMQTTString(&body, username)
+	}
+	if password != "" {
+		writeMQTTString(&body, password)
+	}
+
+	return mqttPacket(0x10, body.Bytes())
+}
+
+// mqttPublish returns an MQTT PUBLISH packet with QoS 0.
+func mqttPublish(topic string, payload []byte, retain bool) []byte {
+	var body bytes.Buffer
+	writeMQTTString(&body, topic)
+	body.Write(payload)
+
+	typ := byte(0x30)
+	if retain {
+		typ |= 0x01
+	}
+	return mqttPacket(typ, body.Bytes())
+}
+
+func mqttPacket(typ byte, body []byte) []byte {
+	pkt := []byte{typ}
+
+	// The remaining length is encoded 7 bits at a time.
+	n := len(body)
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		pkt = append(pkt, b)
+		if n == 0 {
+			break
+		}
+	}
+	return append(pkt, body...)
+}
+
+// readMQTTPacket reads a packet, returning its type and flags and its body.
+func readMQTTPacket(r io.Reader) (byte, []byte, error) {
+	var b [1]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, nil, err
+	}
+	typ := b[0]
+
+	var n int
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, errors.New("malformed remaining length")
+		}
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return 0, nil, err
+		}
+		n |= int(b[0]&0x7f) << (7 * i)
+		if b[0]&0x80 == 0 {
+			break
+		}
+	}
+
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return typ, body, nil
+}
+
+// checkMQTTConnack checks that the packet is a CONNACK accepting the
+// connection.
+func checkMQTTConnack(typ byte, body []byte) error {
+	switch {
+	case typ != 0x20 || len(body) != 2:
+		return fmt.Errorf("unexpected packet type %#x, expected connack", typ)
+	case body[1] != 0:
+		return fmt.Errorf("connection refused: %s", mqttConnackReason(body[1]))
+	}
+	return nil
+}
+
+// mqttConnackReason describes the return code of a refused connection.
+func mqttConnackReason(code byte) string {
+	switch code {
+	case 1:
+		return "unacceptable protocol version"
+	case 2:
+		return "identifier rejected"
+	case 3:
+		return "server unavailable"
+	case 4:
+		return "bad user name or password"
+	case 5:
+		return "not authorized"
+	default:
+		return fmt.Sprintf("return code %d", code)
+	}
+}
+
+func writeMQTTString(buf *bytes.Buffer, s string) {
+	_ = binary.Write(buf, binary.BigEndian, uint16(len(s))) //nolint:gosec // Strings are short.
+	buf.WriteString(s)
+}
@@ -0,0 +1,178 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// mqttConnectTimeout is the time to wait for the broker to accept a connection.
+const mqttConnectTimeout = 10 * time.Second
+
+// mqttPublisher publishes messages to an MQTT broker using QoS 0,
+// connecting lazily and reconnecting after the connection is lost.
+type mqttPublisher struct {
+	cfg  MQTT
+	dial func(ctx context.Context, url string) (io.ReadWriteCloser, error)
+
+	mu   sync.Mutex
+	conn *mqttConn
+}
+
+// mqttConn is a connection to the broker, kept alive by pinging it.
+type mqttConn struct {
+	rwc io.ReadWriteCloser
+
+	// wmu serialises writes of publishes and pings.
+	wmu sync.Mutex
+
+	// seen is the time a packet was last received, in Unix nanoseconds.
+	seen atomic.Int64
+
+	done chan struct{}
+	once sync.Once
+}
+
+func (c *mqttConn) write(b []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	_, err := c.rwc.Write(b)
+	return err
+}
+
+func (c *mqttConn) close() {
+	c.once.Do(func() {
+		close(c.done)
+		_ = c.rwc.Close()
+	})
+}
+
+func (c *mqttConn) isClosed() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Publish publishes the payload to the topic.
+func (p *mqttPublisher) Publish(ctx context.Context, topic string, payload []byte, retain bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn != nil && p.conn.isClosed() {
+		p.conn = nil
+	}
+	if p.conn == nil {
+		conn, err := p.connect(ctx)
+		if err != nil {
+			return fmt.Errorf("connecting to broker: %w", err)
+		}
+		p.conn = conn
+	}
+
+	if err := p.conn.write(mqttPublish(topic, payload, retain)); err != nil {
+		p.conn.close()
+		p.conn = nil
+		return fmt.Errorf("publishing to %q: %w", topic, err)
+	}
+	return nil
+}
+
+// connect connects to the broker, waiting for it to accept the connection.
+func (p *mqttPublisher) connect(ctx context.Context) (*mqttConn, error) {
+	ctx, cancel := context.WithTimeout(ctx, mqttConnectTimeout)
+	defer cancel()
+
+	rwc, err := p.dial(ctx, p.cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	conn := &mqttConn{rwc: rwc, done: make(chan struct{})}
+	if err = conn.write(mqttConnect(p.cfg.ClientID, p.cfg.Username, p.cfg.Password, p.cfg.KeepAlive)); err != nil {
+		conn.close()
+		return nil, err
+	}
+
+	// Reading blocks until the broker answers, so the connection is
+	// closed to stop waiting when the context is done.
+	stop := context.AfterFunc(ctx, conn.close)
+	typ, body, err := readMQTTPacket(rwc)
+	stop()
+	switch {
+	case ctx.Err() != nil:
+		conn.close()
+		return nil, fmt.Errorf("waiting for connack: %w", ctx.Err())
+	case err != nil:
+		conn.close()
+		return nil, fmt.Errorf("reading connack: %w", err)
+	}
+	if err = checkMQTTConnack(typ, body); err != nil {
+		conn.close()
+		return nil, err
+	}
+	conn.seen.Store(time.Now().UnixNano())
+
+	go p.read(conn)
+	if p.cfg.KeepAlive > 0 {
+		go p.keepAlive(conn)
+	}
+	return conn, nil
+}
+
+// read reads the packets sent by the broker, which are ping responses as
+// nothing is subscribed to, until the connection is closed.
+func (p *mqttPublisher) read(conn *mqttConn) {
+	defer conn.close()
+
+	for {
+		if _, _, err := readMQTTPacket(conn.rwc); err != nil {
+			return
+		}
+		conn.seen.Store(time.Now().UnixNano())
+	}
+}
+
+// keepAlive pings the broker within the keep alive interval, closing the
+// connection when the broker stops responding.
+func (p *mqttPublisher) keepAlive(conn *mqttConn) {
+	ticker := time.NewTicker(p.cfg.KeepAlive / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-conn.done:
+			return
+		case <-ticker.C:
+		}
+
+		if time.Since(time.Unix(0, conn.seen.Load())) > p.cfg.KeepAlive*3/2 {
+			conn.close()
+			return
+		}
+		if err := conn.write(mqttPacket(0xc0, nil)); err != nil {
+			conn.close()
+			return
+		}
+	}
+}
+
+// Close closes the connection to the broker.
+func (p *mqttPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil {
+		return nil
+	}
+	p.conn.close()
+	p.conn = nil
+	return nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMQTTPacket(t *testing.T) {
+	tests := []struct {
+		size   int
+		header []byte
+	}{
+		{size: 0, header: []byte{0x30, 0x00}},
+		{size: 127, header: []byte{0x30, 0x7f}},
+		{size: 128, header: []byte{0x30, 0x80, 0x01}},
+		{size: 16383, header: []byte{0x30, 0xff, 0x7f}},
+		{size: 16384, header: []byte{0x30, 0x80, 0x80, 0x01}},
+	}
+
+	for _, test := range tests {
+		body := bytes.Repeat([]byte{'x'}, test.size)
+
+		pkt := mqttPacket(0x30, body)
+
+		if !bytes.HasPrefix(pkt, test.header) || len(pkt) != len(test.header)+test.size {
+			t.Errorf("%d bytes: got header % x, want % x", test.size, pkt[:min(len(pkt), len(test.header))], test.header)
+			continue
+		}
+		typ, got, err := readMQTTPacket(bytes.NewReader(pkt))
+		if err != nil {
+			t.Errorf("%d bytes: %v", test.size, err)
+			continue
+		}
+		if typ != 0x30 || !bytes.Equal(got, body) {
+			t.Errorf("%d bytes: got packet type %#x with %d bytes", test.size, typ, len(got))
+		}
+	}
+}
+
+func TestReadMQTTPacketMalformed(t *testing.T) {
+	if _, _, err := readMQTTPacket(bytes.NewReader([]byte{0x30, 0x80, 0x80, 0x80, 0x80, 0x01})); err == nil {
+		t.Error("read a remaining length of five bytes")
+	}
+	if _, _, err := readMQTTPacket(bytes.NewReader([]byte{0x30, 0x05, 'a'})); err == nil {
+		t.Error("read a truncated packet")
+	}
+}
+
+func TestMQTTConnect(t *testing.T) {
+	typ, body, err := readMQTTPacket(bytes.NewReader(mqttConnect("mirror", "user", "pass", time.Minute)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []byte{
+		0x00, 0x04, 'M', 'Q', 'T', 'T',
+		0x04,       // Protocol level.
+		0xc2,       // Username, password and clean session.
+		0x00, 0x3c, // Keep alive.
+		0x00, 0x06, 'm', 'i', 'r', 'r', 'o', 'r',
+		0x00, 0x04, 'u', 's', 'e', 'r',
+		0x00, 0x04, 'p', 'a', 's', 's',
+	}
+	if typ != 0x10 || !bytes.Equal(body, want) {
+		t.Errorf("got packet type %#x with body % x, want % x", typ, body, want)
+	}
+
+	_, body, err = readMQTTPacket(bytes.NewReader(mqttConnect("mirror", "", "", 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body[7] != 0x02 || len(body) != 18 {
+		t.Errorf("got anonymous connect % x", body)
+	}
+}
+
+func TestMQTTPublish(t *testing.T) {
+	typ, body, err := readMQTTPacket(bytes.NewReader(mqttPublish("calendar/next", []byte(`{"title":"Standup"}`), true)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := append([]byte{0x00, 0x0d}, `calendar/next{"title":"Standup"}`...)
+	if typ != 0x31 || !bytes.Equal(body, want) {
+		t.Errorf("got packet type %#x with body %q, want retained %q", typ, body, want)
+	}
+}
+
+func TestCheckMQTTConnack(t *testing.T) {
+	tests := []struct {
+		name    string
+		typ     byte
+		body    []byte
+		wantErr string
+	}{
+		{name: "accepted", typ: 0x20, body: []byte{0x00, 0x00}},
+		{name: "bad credentials", typ: 0x20, body: []byte{0x00, 0x04}, wantErr: "connection refused: bad user name or password"},
+		{name: "not authorized", typ: 0x20, body: []byte{0x00, 0x05}, wantErr: "connection refused: not authorized"},
+		{name: "unknown code", typ: 0x20, body: []byte{0x00, 0x80}, wantErr: "connection refused: return code 128"},
+		{name: "ping response", typ: 0xd0, wantErr: "expected connack"},
+		{name: "short", typ: 0x20, body: []byte{0x00}, wantErr: "expected connack"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			typ, body, err := readMQTTPacket(bytes.NewReader(mqttPacket(test.typ, test.body)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = checkMQTTConnack(typ, body)
+
+			switch {
+			case test.wantErr == "" && err != nil:
+				t.Errorf("got error %v", err)
+			case test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)):
+				t.Errorf("got error %v, want %q", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/render"
+)
+
+// notification is the payload posted to a webhook before an event starts.
+type notification struct {
+	Module   string    `json:"module"`
+	Title    string    `json:"title"`
+	Start    time.Time `json:"start"`
+	LeadTime string    `json:"leadTime"`
+}
+
+// notifier posts webhook notifications before events start.
+type notifier struct {
+	module string
+	client *http.Client
+	cfg    []Notify
+
+	sent map[string]time.Time
+}
+
+func newNotifier(module string, client *http.Client, cfg []Notify) *notifier {
+	return &notifier{
+		module: module,
+		client: client,
+		cfg:    cfg,
+		sent:   map[string]time.Time{},
+	}
+}
+
+// check notifies each webhook of timed events starting within its lead time,
+// notifying at most once per event.
+func (n *notifier) check(ctx context.Context, now time.Time, events []render.Event) []error {
+	for key, start := range n.sent {
+		if start.Before(now) {
+			delete(n.sent, key)
+		}
+	}
+
+	var errs []error
+	for i, cfg := range n.cfg {
+		for _, evnt := range events {
+			if evnt.IsAllDay || !evnt.Time.After(now) || evnt.Time.Sub(now) > cfg.LeadTime {
+				continue
+			}
+
+			key := strconv.Itoa(i) + ":" + evnt.Time.String() + ":" + evnt.Title
+			if _, ok := n.sent[key]; ok {
+				continue
+			}
+			n.sent[key] = evnt.Time
+
+			if err := n.post(ctx, cfg, evnt); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+func (n *notifier) post(ctx context.Context, cfg Notify, evnt render.Event) error {
+	b, err := json.Marshal(notification{
+		Module:   n.module,
+		Title:    evnt.Title,
+		Start:    evnt.Time,
+		LeadTime: cfg.LeadTime.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("encoding notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting notification to %q: %w", cfg.URL, err)
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("posting notification to %q: unexpected status code %d", cfg.URL, resp.StatusCode)
+	}
+	return nil
+}
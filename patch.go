@@ -0,0 +1,116 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"honnef.co/go/js/dom/v2"
+)
+
+// keyAttrs are the attributes giving elements a stable identity across
+// renders, so the nodes of an event are kept when others come and go.
+var keyAttrs = []string{"data-event", "data-alarm"}
+
+// patch updates the contents of el to the given html, changing only the
+// nodes that differ rather than replacing all of them, which would cause
+// flicker and restart CSS transitions and animations.
+func patch(el dom.Element, html string) {
+	tmpl := dom.GetWindow().Document().CreateElement("template")
+	tmpl.SetInnerHTML(html)
+	patchChildren(el, dom.WrapNode(tmpl.Underlying().Get("content")))
+}
+
+// patchChildren updates the children of node to match the children of
+// want, which are moved into node when added.
+func patchChildren(node, want dom.Node) {
+	keyed := map[string]dom.Node{}
+	for _, child := range node.ChildNodes() {
+		if key := nodeKey(child); key != "" {
+			keyed[key] = child
+		}
+	}
+
+	cur := node.FirstChild()
+	for _, child := range want.ChildNodes() {
+		var match dom.Node
+		if key := nodeKey(child); key != "" {
+			match = keyed[key]
+			delete(keyed, key)
+		} else if cur != nil && nodeKey(cur) == "" && sameKind(cur, child) {
+			match = cur
+		}
+
+		if match != nil && !sameKind(match, child) {
+			if isNode(match, cur) {
+				cur = cur.NextSibling()
+			}
+			node.RemoveChild(match)
+			match = nil
+		}
+		if match == nil {
+			node.InsertBefore(child, cur)
+			continue
+		}
+
+		if isNode(match, cur) {
+			cur = cur.NextSibling()
+		} else {
+			node.InsertBefore(match, cur)
+		}
+		patchNode(match, child)
+	}
+
+	// Anything left over is no longer rendered.
+	for cur != nil {
+		next := cur.NextSibling()
+		node.RemoveChild(cur)
+		cur = next
+	}
+}
+
+// patchNode updates node to match want, which must be of the same kind.
+func patchNode(node, want dom.Node) {
+	el, ok := node.(dom.Element)
+	if !ok {
+		if node.NodeValue() != want.NodeValue() {
+			node.SetNodeValue(want.NodeValue())
+		}
+		return
+	}
+
+	attrs := want.(dom.Element).Attributes()
+	for name := range el.Attributes() {
+		if _, ok = attrs[name]; !ok {
+			el.RemoveAttribute(name)
+		}
+	}
+	for name, val := range attrs {
+		if el.GetAttribute(name) != val {
+			el.SetAttribute(name, val)
+		}
+	}
+	patchChildren(node, want)
+}
+
+// nodeKey returns the stable identity of the node, if any.
+func nodeKey(node dom.Node) string {
+	el, ok := node.(dom.Element)
+	if !ok {
+		return ""
+	}
+	for _, attr := range keyAttrs {
+		if el.HasAttribute(attr) {
+			return el.TagName() + " " + attr + "=" + el.GetAttribute(attr)
+		}
+	}
+	return ""
+}
+
+// isNode reports whether a and b are the same node.
+func isNode(a, b dom.Node) bool {
+	return a != nil && b != nil && a.Underlying().Equal(b.Underlying())
+}
+
+// sameKind reports whether the nodes are of the same type and tag.
+func sameKind(a, b dom.Node) bool {
+	return a.NodeType() == b.NodeType() && a.NodeName() == b.NodeName()
+}
@@ -0,0 +1,92 @@
+package ical
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Alarm is a reminder of an event.
+type Alarm struct {
+	// Offset is the time of the alarm relative to the start of the
+	// event, or to its end when RelatedEnd is set. It is negative for
+	// alarms before the event.
+	Offset     time.Duration
+	RelatedEnd bool
+
+	// At is the absolute time of the alarm, overriding its offset.
+	At time.Time
+
+	Action      string
+	Description string
+}
+
+// Time returns the time the alarm triggers for the event.
+func (a Alarm) Time(evnt Event) time.Time {
+	switch {
+	case !a.At.IsZero():
+		return a.At
+	case a.RelatedEnd:
+		return evnt.End.Add(a.Offset)
+	default:
+		return evnt.Start.Add(a.Offset)
+	}
+}
+
+// parseDuration parses an iCalendar duration, such as "-PT15M" or "P1DT2H".
+func parseDuration(s string) (time.Duration, error) {
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign, s = -1, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") || len(s) < 3 {
+		return 0, errors.New("invalid duration")
+	}
+
+	var (
+		d      time.Duration
+		inTime bool
+		num    int
+		digits bool
+	)
+	for _, c := range s[1:] {
+		switch {
+		case c >= '0' && c <= '9':
+			num = num*10 + int(c-'0')
+			digits = true
+			continue
+		case c == 'T':
+			inTime = true
+			continue
+		}
+		if !digits {
+			return 0, errors.New("invalid duration")
+		}
+
+		var unit time.Duration
+		switch {
+		case c == 'W' && !inTime:
+			unit = 7 * 24 * time.Hour
+		case c == 'D' && !inTime:
+			unit = 24 * time.Hour
+		case c == 'H' && inTime:
+			unit = time.Hour
+		case c == 'M' && inTime:
+			unit = time.Minute
+		case c == 'S' && inTime:
+			unit = time.Second
+		default:
+			return 0, errors.New("invalid duration unit " + strconv.QuoteRune(c))
+		}
+		d += time.Duration(num) * unit
+		num, digits = 0, false
+	}
+	if digits {
+		return 0, errors.New("invalid duration")
+	}
+	return sign * d, nil
+}
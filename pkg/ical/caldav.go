@@ -0,0 +1,286 @@
+package ical
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CalDAV is a client of a CalDAV server.
+type CalDAV struct {
+	// Fetcher validates, rate limits and performs all requests.
+	Fetcher *Fetcher
+
+	// URL is the server URL, from which the principal of the user is
+	// discovered.
+	URL string
+
+	Username string
+	Password string
+}
+
+// CalDAVCalendar is a calendar collection on a CalDAV server.
+type CalDAVCalendar struct {
+	URL  string
+	Name string
+
+	// Events and Tasks report whether the calendar holds events and
+	// to-dos, e.g. iCloud keeps reminders in calendars of their own.
+	Events bool
+	Tasks  bool
+}
+
+// CalDAVObject is a calendar object on a CalDAV server.
+type CalDAVObject struct {
+	URL  string
+	ETag string
+	Data []byte
+}
+
+// Calendars discovers the event and task calendars of the user, following
+// the current user principal to its calendar home.
+func (c *CalDAV) Calendars(ctx context.Context) ([]CalDAVCalendar, error) {
+	principal, err := c.findHref(ctx, c.URL, `<d:current-user-principal/>`, func(p davProp) string {
+		return p.CurrentUserPrincipal.Href
+	})
+	if err != nil {
+		return nil, fmt.Errorf("finding principal: %w", err)
+	}
+	home, err := c.findHref(ctx, principal, `<c:calendar-home-set/>`, func(p davProp) string {
+		return p.CalendarHomeSet.Href
+	})
+	if err != nil {
+		return nil, fmt.Errorf("finding calendar home: %w", err)
+	}
+
+	ms, base, err := c.propfind(ctx, home, "1", `<d:resourcetype/><d:displayname/><c:supported-calendar-component-set/>`)
+	if err != nil {
+		return nil, fmt.Errorf("listing calendars: %w", err)
+	}
+	var cals []CalDAVCalendar
+	for _, resp := range ms.Responses {
+		prop, ok := resp.prop()
+		if !ok || prop.ResourceType.Calendar == nil {
+			continue
+		}
+		evnts, tasks := prop.supports("VEVENT"), prop.supports("VTODO")
+		if !evnts && !tasks {
+			continue
+		}
+		u, err := base.Parse(resp.Href)
+		if err != nil {
+			continue
+		}
+		cals = append(cals, CalDAVCalendar{URL: u.String(), Name: prop.DisplayName, Events: evnts, Tasks: tasks})
+	}
+	return cals, nil
+}
+
+// Events returns the iCalendar data of the events of the calendar
+// overlapping the window between start and end, one calendar object
+// per event.
+func (c *CalDAV) Events(ctx context.Context, calURL string, start, end time.Time) ([][]byte, error) {
+	body := `<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">` +
+		`<d:prop><c:calendar-data/></d:prop>` +
+		`<c:filter><c:comp-filter name="VCALENDAR"><c:comp-filter name="VEVENT">` +
+		`<c:time-range start="` + start.UTC().Format(davTimeFormat) + `" end="` + end.UTC().Format(davTimeFormat) + `"/>` +
+		`</c:comp-filter></c:comp-filter></c:filter></c:calendar-query>`
+
+	ms, _, err := c.do(ctx, "REPORT", calURL, "1", body)
+	if err != nil {
+		return nil, fmt.Errorf("querying events: %w", err)
+	}
+	var objs [][]byte
+	for _, resp := range ms.Responses {
+		if prop, ok := resp.prop(); ok && prop.CalendarData != "" {
+			objs = append(objs, []byte(prop.CalendarData))
+		}
+	}
+	return objs, nil
+}
+
+// Tasks returns the to-dos of the calendar within the window between
+// start and end, one calendar object per to-do, along with the URL and
+// ETag needed to update it.
+func (c *CalDAV) Tasks(ctx context.Context, calURL string, start, end time.Time) ([]CalDAVObject, error) {
+	body := `<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">` +
+		`<d:prop><d:getetag/><c:calendar-data/></d:prop>` +
+		`<c:filter><c:comp-filter name="VCALENDAR"><c:comp-filter name="VTODO">` +
+		`<c:time-range start="` + start.UTC().Format(davTimeFormat) + `" end="` + end.UTC().Format(davTimeFormat) + `"/>` +
+		`</c:comp-filter></c:comp-filter></c:filter></c:calendar-query>`
+
+	ms, base, err := c.do(ctx, "REPORT", calURL, "1", body)
+	if err != nil {
+		return nil, fmt.Errorf("querying tasks: %w", err)
+	}
+	var objs []CalDAVObject
+	for _, resp := range ms.Responses {
+		prop, ok := resp.prop()
+		if !ok || prop.CalendarData == "" {
+			continue
+		}
+		u, err := base.Parse(resp.Href)
+		if err != nil {
+			continue
+		}
+		objs = append(objs, CalDAVObject{URL: u.String(), ETag: prop.ETag, Data: []byte(prop.CalendarData)})
+	}
+	return objs, nil
+}
+
+// Put creates the calendar object with the given name in the calendar,
+// failing if an object of that name already exists.
+func (c *CalDAV) Put(ctx context.Context, calURL, name string, data []byte) error {
+	base, err := url.Parse(calURL)
+	if err != nil {
+		return fmt.Errorf("parsing url: %w", err)
+	}
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	return c.put(ctx, base.JoinPath(name), "If-None-Match", "*", data)
+}
+
+// Update replaces the calendar object at objURL, failing with a 412
+// status if it changed since it was read with the given ETag.
+func (c *CalDAV) Update(ctx context.Context, objURL, etag string, data []byte) error {
+	u, err := url.Parse(objURL)
+	if err != nil {
+		return fmt.Errorf("parsing url: %w", err)
+	}
+	if etag == "" {
+		return errors.New("the calendar object has no etag")
+	}
+	return c.put(ctx, u, "If-Match", etag, data)
+}
+
+// put writes the calendar object at u on the given precondition.
+func (c *CalDAV) put(ctx context.Context, u *url.URL, cond, value string, data []byte) error {
+	header := c.header()
+	header.Set("Content-Type", "text/calendar; charset=utf-8")
+	header.Set(cond, value)
+
+	_, err := c.Fetcher.Send(ctx, http.MethodPut, u.String(), header, data)
+	return err
+}
+
+// header returns the headers of a request, authenticating the user.
+func (c *CalDAV) header() http.Header {
+	header := http.Header{}
+	header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(c.Username+":"+c.Password)))
+	return header
+}
+
+const davTimeFormat = "20060102T150405Z"
+
+// findHref returns the absolute URL of the href property selected by fn.
+func (c *CalDAV) findHref(ctx context.Context, rawURL, prop string, fn func(davProp) string) (string, error) {
+	ms, base, err := c.propfind(ctx, rawURL, "0", prop)
+	if err != nil {
+		return "", err
+	}
+	for _, resp := range ms.Responses {
+		p, ok := resp.prop()
+		if !ok || strings.TrimSpace(fn(p)) == "" {
+			continue
+		}
+		u, err := base.Parse(strings.TrimSpace(fn(p)))
+		if err != nil {
+			return "", fmt.Errorf("parsing href: %w", err)
+		}
+		return u.String(), nil
+	}
+	return "", errors.New("property not found")
+}
+
+func (c *CalDAV) propfind(ctx context.Context, rawURL, depth, props string) (*davMultistatus, *url.URL, error) {
+	body := `<d:propfind xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav"><d:prop>` + props + `</d:prop></d:propfind>`
+	return c.do(ctx, "PROPFIND", rawURL, depth, body)
+}
+
+// do performs a WebDAV request, returning the multistatus response and
+// the URL it was served from, against which hrefs are resolved.
+func (c *CalDAV) do(ctx context.Context, method, rawURL, depth, body string) (*davMultistatus, *url.URL, error) {
+	header := c.header()
+	header.Set("Content-Type", "application/xml; charset=utf-8")
+	header.Set("Depth", depth)
+
+	resp, err := c.Fetcher.Send(ctx, method, rawURL, header, []byte(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, nil, &StatusError{Code: resp.StatusCode, Body: ErrorBody(bytes.NewReader(resp.Body)), Header: resp.Header}
+	}
+
+	var ms davMultistatus
+	if err = xml.Unmarshal(resp.Body, &ms); err != nil {
+		return nil, nil, fmt.Errorf("parsing response: %w", err)
+	}
+	return &ms, resp.URL, nil
+}
+
+type davMultistatus struct {
+	Responses []davResponse `xml:"DAV: response"`
+}
+
+type davResponse struct {
+	Href      string        `xml:"DAV: href"`
+	Propstats []davPropstat `xml:"DAV: propstat"`
+}
+
+// prop returns the properties found for the response.
+func (r davResponse) prop() (davProp, bool) {
+	for _, ps := range r.Propstats {
+		if strings.Contains(ps.Status, " 200 ") {
+			return ps.Prop, true
+		}
+	}
+	return davProp{}, false
+}
+
+type davPropstat struct {
+	Prop   davProp `xml:"DAV: prop"`
+	Status string  `xml:"DAV: status"`
+}
+
+type davProp struct {
+	CurrentUserPrincipal davHref `xml:"DAV: current-user-principal"`
+	CalendarHomeSet      davHref `xml:"urn:ietf:params:xml:ns:caldav calendar-home-set"`
+	DisplayName          string  `xml:"DAV: displayname"`
+	ResourceType         struct {
+		Calendar *struct{} `xml:"urn:ietf:params:xml:ns:caldav calendar"`
+	} `xml:"DAV: resourcetype"`
+	ComponentSet struct {
+		Comps []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"urn:ietf:params:xml:ns:caldav comp"`
+	} `xml:"urn:ietf:params:xml:ns:caldav supported-calendar-component-set"`
+	CalendarData string `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+	ETag         string `xml:"DAV: getetag"`
+}
+
+// supports reports whether the calendar supports the component. Servers
+// that do not report their supported components are assumed to support it.
+func (p davProp) supports(comp string) bool {
+	if len(p.ComponentSet.Comps) == 0 {
+		return true
+	}
+	for _, c := range p.ComponentSet.Comps {
+		if strings.EqualFold(c.Name, comp) {
+			return true
+		}
+	}
+	return false
+}
+
+type davHref struct {
+	Href string `xml:"DAV: href"`
+}
@@ -0,0 +1,76 @@
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	dateFormat     = "20060102"
+	dateTimeFormat = "20060102T150405Z"
+)
+
+// Encode writes the events to w as a calendar. Instances of recurring
+// events are written as individual events.
+func Encode(w io.Writer, events []Event) error {
+	bw := bufio.NewWriter(w)
+	stamp := time.Now().UTC().Format(dateTimeFormat)
+
+	writeLine(bw, "BEGIN:VCALENDAR")
+	writeLine(bw, "VERSION:2.0")
+	writeLine(bw, "PRODID:-//glasslabs//calendar//EN")
+	for _, evnt := range events {
+		uid := evnt.UID
+		if evnt.IsRecurring || uid == "" {
+			uid += "-" + evnt.Start.UTC().Format(dateTimeFormat)
+		}
+
+		writeLine(bw, "BEGIN:VEVENT")
+		writeLine(bw, "UID:"+escape(uid))
+		writeLine(bw, "DTSTAMP:"+stamp)
+		if evnt.AllDay {
+			writeLine(bw, "DTSTART;VALUE=DATE:"+evnt.Start.Format(dateFormat))
+			writeLine(bw, "DTEND;VALUE=DATE:"+evnt.End.Add(time.Millisecond).Format(dateFormat))
+		} else {
+			writeLine(bw, "DTSTART:"+evnt.Start.UTC().Format(dateTimeFormat))
+			writeLine(bw, "DTEND:"+evnt.End.UTC().Format(dateTimeFormat))
+		}
+		writeLine(bw, "SUMMARY:"+escape(evnt.Summary))
+		if evnt.Location != "" {
+			writeLine(bw, "LOCATION:"+escape(evnt.Location))
+		}
+		if evnt.Description != "" {
+			writeLine(bw, "DESCRIPTION:"+escape(evnt.Description))
+		}
+		writeLine(bw, "END:VEVENT")
+	}
+	writeLine(bw, "END:VCALENDAR")
+
+	return bw.Flush()
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// writeLine writes a content line, folding it at 75 octets.
+func writeLine(w *bufio.Writer, line string) {
+	maxLen := 75
+	for len(line) > maxLen {
+		// Avoid splitting multi-byte characters.
+		i := maxLen
+		for i > 0 && line[i]&0xc0 == 0x80 {
+			i--
+		}
+		_, _ = w.WriteString(line[:i] + "\r\n ")
+		line = line[i:]
+
+		// Continuation lines start with a space.
+		maxLen = 74
+	}
+	_, _ = w.WriteString(line + "\r\n")
+}
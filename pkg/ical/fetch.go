@@ -0,0 +1,289 @@
+package ical
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// StatusError is returned when a calendar responds with an unexpected status.
+type StatusError struct {
+	Code   int
+	Body   string
+	Header http.Header
+}
+
+// Error returns the error message.
+func (e *StatusError) Error() string {
+	return strconv.Itoa(e.Code) + " " + e.Body
+}
+
+// Fetcher fetches calendar feeds over HTTP.
+type Fetcher struct {
+	// Client is the HTTP client used for all requests.
+	Client *http.Client
+
+	// UserAgent is sent on every request when set.
+	UserAgent string
+
+	// MaxRedirects is the number of redirects followed. Redirects
+	// from https to http are always refused.
+	MaxRedirects int
+
+	// AllowedHosts restricts the hosts calendars are fetched from.
+	// Entries starting with a "." match any subdomain.
+	AllowedHosts []string
+
+	// Limiter limits the rate of requests to each host.
+	Limiter *HostLimiter
+
+	// MaxBodySize is the maximum size of a decoded response body in
+	// bytes, guarding against huge or maliciously compressed feeds.
+	// Zero means no limit.
+	MaxBodySize int64
+}
+
+// DefaultMaxBodySize is the default maximum size of a response body.
+const DefaultMaxBodySize = 20 << 20
+
+// maxErrorBodySize is the maximum size of an error response body kept
+// in a StatusError.
+const maxErrorBodySize = 4 << 10
+
+// ErrBodyTooLarge is returned when a response body exceeds the maximum size.
+var ErrBodyTooLarge = errors.New("response body too large")
+
+// ErrNotModified is returned when a calendar has not changed since it
+// was fetched with the given validators.
+var ErrNotModified = errors.New("calendar not modified")
+
+// Validators are the cache validators of a fetched calendar. Sending
+// them on the next fetch lets the server answer that the calendar has
+// not changed, rather than sending it again.
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+func newValidators(header http.Header) Validators {
+	return Validators{
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+	}
+}
+
+// NewFetcher returns a fetcher with default settings.
+func NewFetcher() *Fetcher {
+	f := &Fetcher{
+		MaxRedirects: 5,
+		MaxBodySize:  DefaultMaxBodySize,
+	}
+	f.Client = &http.Client{CheckRedirect: f.CheckRedirect}
+	return f
+}
+
+// Fetch returns the decoded body of the calendar at rawURL.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	return f.Request(ctx, http.MethodGet, rawURL, nil, nil)
+}
+
+// Request performs a request to rawURL with the given headers and body,
+// returning the decoded body of a successful response. It is used to
+// fetch events from APIs rather than calendar feeds.
+func (f *Fetcher) Request(ctx context.Context, method, rawURL string, header http.Header, body []byte) ([]byte, error) {
+	resp, err := f.Send(ctx, method, rawURL, header, body)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// FetchIfModified returns the decoded body of the calendar at rawURL with
+// its validators, or ErrNotModified when the calendar has not changed
+// since it was fetched with the given validators.
+func (f *Fetcher) FetchIfModified(ctx context.Context, rawURL string, header http.Header, v Validators) ([]byte, Validators, error) {
+	header = header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	if v.ETag != "" {
+		header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		header.Set("If-Modified-Since", v.LastModified)
+	}
+
+	resp, err := f.Send(ctx, http.MethodGet, rawURL, header, nil)
+	if err != nil {
+		return nil, Validators{}, err
+	}
+	return resp.Body, newValidators(resp.Header), nil
+}
+
+// Response is a successful response to a request.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+
+	// URL is the URL the response was served from, after redirects.
+	URL *url.URL
+
+	// Body is the decoded body, limited to the maximum body size.
+	Body []byte
+}
+
+// Send performs a request to rawURL with the given method, headers and
+// body, returning the successful response. Responses without a 2xx
+// status are returned as a StatusError, apart from 304 which is
+// returned as ErrNotModified.
+//
+// All requests of the fetcher and of the clients built on it are sent
+// here, so the URL and redirect checks, the rate limit and the body size
+// limit apply to each of them.
+func (f *Fetcher) Send(ctx context.Context, method, rawURL string, header http.Header, body []byte) (*Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing url: %w", err)
+	}
+	if err = f.ValidateURL(u); err != nil {
+		return nil, err
+	}
+
+	if err = f.Limiter.Wait(ctx, u.Host); err != nil {
+		return nil, fmt.Errorf("waiting to request calendar: %w", err)
+	}
+
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, r)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if f.MaxRedirects <= 0 {
+		// The browser fetch API follows redirects itself, so
+		// they must be refused there as well.
+		req.Header.Set("js.fetch:redirect", "error")
+	}
+	if f.UserAgent != "" {
+		req.Header.Set("User-Agent", f.UserAgent)
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("requesting calendar: %w", err)
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	// The fetch API follows redirects without consulting the client,
+	// so the final URL is checked as well.
+	if resp.Request != nil && resp.Request.URL.String() != rawURL {
+		if err = f.CheckRedirect(resp.Request, []*http.Request{req}); err != nil {
+			return nil, fmt.Errorf("requesting calendar: %w", err)
+		}
+		u = resp.Request.URL
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, ErrNotModified
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("fetching calendar: %w", &StatusError{Code: resp.StatusCode, Body: ErrorBody(resp.Body), Header: resp.Header})
+	}
+
+	dec, err := decodeBody(resp)
+	if err != nil {
+		return nil, fmt.Errorf("decoding calendar: %w", err)
+	}
+	b, err := f.readBody(dec)
+	if err != nil {
+		return nil, fmt.Errorf("reading calendar: %w", err)
+	}
+	return &Response{StatusCode: resp.StatusCode, Header: resp.Header, URL: u, Body: b}, nil
+}
+
+// readBody reads r up to the maximum body size, returning ErrBodyTooLarge
+// if it is exceeded.
+func (f *Fetcher) readBody(r io.Reader) ([]byte, error) {
+	if f.MaxBodySize <= 0 {
+		return io.ReadAll(r)
+	}
+	b, err := io.ReadAll(io.LimitReader(r, f.MaxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > f.MaxBodySize {
+		return nil, fmt.Errorf("%w: exceeds %d bytes", ErrBodyTooLarge, f.MaxBodySize)
+	}
+	return b, nil
+}
+
+// ErrorBody returns the start of the body of an error response.
+func ErrorBody(r io.Reader) string {
+	b, _ := io.ReadAll(io.LimitReader(r, maxErrorBodySize))
+	return string(b)
+}
+
+// CheckRedirect limits the number of redirects followed and refuses
+// scheme downgrades and disallowed hosts.
+func (f *Fetcher) CheckRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) > f.MaxRedirects {
+		return fmt.Errorf("stopped after %d redirects", f.MaxRedirects)
+	}
+	if prev := via[len(via)-1]; prev.URL.Scheme == "https" && req.URL.Scheme != "https" {
+		return fmt.Errorf("refusing redirect from https to %s", req.URL.Scheme)
+	}
+	return f.ValidateURL(req.URL)
+}
+
+// ValidateURL ensures the url uses a supported scheme and an allowed host.
+func (f *Fetcher) ValidateURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if len(f.AllowedHosts) == 0 {
+		return nil
+	}
+
+	host := u.Hostname()
+	for _, allowed := range f.AllowedHosts {
+		if host == allowed || (strings.HasPrefix(allowed, ".") && strings.HasSuffix(host, allowed)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("host %q is not allowed", host)
+}
+
+// decodeBody returns the response body, decompressing it when needed.
+//
+// The browser fetch API may already have decompressed the body while
+// leaving the Content-Encoding header in place, so the gzip header is
+// sniffed before decompressing.
+func decodeBody(resp *http.Response) (io.Reader, error) {
+	br := bufio.NewReader(resp.Body)
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return br, nil
+	}
+
+	magic, err := br.Peek(2)
+	if err != nil || magic[0] != 0x1f || magic[1] != 0x8b {
+		return br, nil //nolint:nilerr // Not compressed.
+	}
+	return gzip.NewReader(br)
+}
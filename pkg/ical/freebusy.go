@@ -0,0 +1,80 @@
+package ical
+
+import (
+	"strings"
+	"time"
+)
+
+// KindBusy is the kind of the anonymous events of busy periods.
+const KindBusy = "busy"
+
+// period is a busy period of a VFREEBUSY component.
+type period struct {
+	start, end time.Time
+}
+
+// parsePeriods parses the busy periods of a FREEBUSY property. Periods
+// are either a start and end, or a start and duration. Free periods and
+// invalid periods are ignored.
+func parsePeriods(value string, params map[string]string) []period {
+	if params["FBTYPE"] == "FREE" {
+		return nil
+	}
+
+	var periods []period
+	for _, p := range strings.Split(value, ",") {
+		startStr, endStr, ok := strings.Cut(p, "/")
+		if !ok {
+			continue
+		}
+		start, err := parsePeriodTime(startStr)
+		if err != nil {
+			continue
+		}
+
+		var end time.Time
+		if strings.HasPrefix(endStr, "P") || strings.HasPrefix(endStr, "+P") {
+			d, err := parseDuration(endStr)
+			if err != nil {
+				continue
+			}
+			end = start.Add(d)
+		} else if end, err = parsePeriodTime(endStr); err != nil {
+			continue
+		}
+		if !end.After(start) {
+			continue
+		}
+		periods = append(periods, period{start: start, end: end})
+	}
+	return periods
+}
+
+// parsePeriodTime parses a period time, which should be in UTC, but is
+// otherwise taken as local time.
+func parsePeriodTime(s string) (time.Time, error) {
+	if strings.HasSuffix(s, "Z") {
+		return time.Parse("20060102T150405Z", s)
+	}
+	//nolint:gosmopolitan
+	return time.ParseInLocation("20060102T150405", s, time.Local)
+}
+
+// busyEvents returns the anonymous events of the busy periods within
+// the window between start and end.
+func busyEvents(periods []period, start, end time.Time) []Event {
+	var evnts []Event
+	for _, p := range periods {
+		if !p.start.Before(end) || !p.end.After(start) {
+			continue
+		}
+		evnts = append(evnts, Event{
+			Kind:    KindBusy,
+			UID:     KindBusy + "-" + p.end.UTC().Format("20060102T150405Z"),
+			Summary: "Busy",
+			Start:   p.start,
+			End:     p.end,
+		})
+	}
+	return evnts
+}
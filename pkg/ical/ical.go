@@ -0,0 +1,228 @@
+// Package ical fetches and parses iCalendar feeds.
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apognu/gocal"
+	"github.com/apognu/gocal/parser"
+)
+
+// Event is a calendar event.
+type Event struct {
+	// Calendar is the name of the calendar the event belongs to.
+	Calendar string
+	// Kind is the kind of entry, such as "task" for to-dos and the
+	// tasks of task services, or "busy" and "note" for busy periods and
+	// journal entries. It is empty for calendar events.
+	Kind string
+
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Categories  []string
+	Attendees   []string
+	Geo         *Geo
+
+	Start  time.Time
+	End    time.Time
+	AllDay bool
+
+	IsRecurring    bool
+	RecurrenceRule map[string]string
+
+	Alarms []Alarm
+
+	// Props are the X- properties of the event by name,
+	// e.g. "X-MICROSOFT-CDO-BUSYSTATUS".
+	Props map[string]string
+}
+
+// Geo is the location of an event.
+type Geo struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Calendar is a parsed calendar.
+type Calendar struct {
+	// Name is the name declared by the calendar, if any.
+	Name   string
+	Events []Event
+
+	// Cancelled are the ids of the cancelled events, either the UID of
+	// a cancelled event or series, or the instance id of a cancelled or
+	// excluded instance of a series.
+	Cancelled []string
+}
+
+// InstanceID returns the id of the instance of the event with the UID
+// starting at start.
+func InstanceID(uid string, start time.Time) string {
+	return uid + "/" + start.UTC().Format("20060102T150405Z")
+}
+
+// Parse parses a calendar from r, expanding recurring events and
+// keeping only events within the window between start and end.
+// Floating times are in the timezone declared by the calendar in
+// X-WR-TIMEZONE, if any, or the local timezone.
+func Parse(r io.Reader, start, end time.Time) (*Calendar, error) {
+	return ParseInLocation(r, start, end, nil)
+}
+
+// ParseInLocation is like Parse, but floating times are in the given
+// timezone unless it is nil.
+func ParseInLocation(r io.Reader, start, end time.Time, loc *time.Location) (*Calendar, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading calendar: %w", err)
+	}
+	props := scan(b)
+	if loc == nil && props.timezone != "" {
+		// Unknown timezones are ignored, as they are for events.
+		loc, _ = time.LoadLocation(props.timezone)
+	}
+
+	gcal := gocal.NewParser(bytes.NewReader(b))
+	gcal.Start = &start
+	gcal.End = &end
+	if err := gcal.Parse(); err != nil {
+		return nil, fmt.Errorf("parsing calendar: %w", err)
+	}
+
+	floating := func(uid string, t time.Time) time.Time {
+		if loc == nil || !props.floating[uid] {
+			return t
+		}
+		return inLocation(t, loc)
+	}
+
+	// Cancellations are either published as a calendar with the CANCEL
+	// method, or as cancelled components, which for a single instance
+	// override the instance of the series.
+	cancelled := map[string]bool{}
+	for i := range gcal.Events {
+		evnt := &gcal.Events[i]
+		for _, ex := range evnt.ExcludeDates {
+			cancelled[InstanceID(evnt.Uid, floating(evnt.Uid, ex))] = true
+		}
+		if !strings.EqualFold(gcal.Method, "CANCEL") && !strings.EqualFold(evnt.Status, "CANCELLED") {
+			continue
+		}
+		if evnt.RecurrenceID == "" {
+			cancelled[evnt.Uid] = true
+			continue
+		}
+		rid, err := parser.ParseTime(evnt.RecurrenceID, evnt.RawStart.Params, parser.TimeStart, false, gcal.AllDayEventsTZ)
+		if err != nil {
+			continue
+		}
+		cancelled[InstanceID(evnt.Uid, floating(evnt.Uid, *rid))] = true
+	}
+
+	cal := &Calendar{
+		Name:   props.name,
+		Events: make([]Event, 0, len(gcal.Events)),
+	}
+	for id := range cancelled {
+		cal.Cancelled = append(cal.Cancelled, id)
+	}
+	sort.Strings(cal.Cancelled)
+	for i := range gcal.Events {
+		e := newEvent(&gcal.Events[i])
+		e.Alarms = props.alarms[e.UID]
+		e.Start = floating(e.UID, e.Start)
+		e.End = floating(e.UID, e.End)
+		if len(cancelled) > 0 && (cancelled[e.UID] || cancelled[InstanceID(e.UID, e.Start)]) {
+			continue
+		}
+		cal.Events = append(cal.Events, e)
+	}
+	cal.Events = append(cal.Events, busyEvents(props.busy, start, end)...)
+	cal.Events = append(cal.Events, noteEvents(props.journals, start, end)...)
+	cal.Events = append(cal.Events, taskEvents(props.todos, loc, start, end)...)
+	Sort(cal.Events)
+	return cal, nil
+}
+
+func newEvent(evnt *gocal.Event) Event {
+	e := Event{
+		UID:            evnt.Uid,
+		Summary:        evnt.Summary,
+		Description:    evnt.Description,
+		Location:       evnt.Location,
+		Categories:     evnt.Categories,
+		AllDay:         isAllDayEvent(evnt),
+		IsRecurring:    evnt.IsRecurring,
+		RecurrenceRule: evnt.RecurrenceRule,
+		Props:          evnt.CustomAttributes,
+	}
+	if len(evnt.Attendees) > 0 {
+		e.Attendees = make([]string, 0, len(evnt.Attendees))
+	}
+	for _, att := range evnt.Attendees {
+		name := att.Cn
+		if name == "" {
+			name = strings.TrimPrefix(strings.ToLower(att.Value), "mailto:")
+		}
+		e.Attendees = append(e.Attendees, name)
+	}
+	if evnt.Geo != nil {
+		e.Geo = &Geo{Latitude: evnt.Geo.Lat, Longitude: evnt.Geo.Long}
+	}
+	if evnt.Start != nil {
+		e.Start = *evnt.Start
+	}
+	if evnt.End != nil {
+		e.End = *evnt.End
+	}
+	return e
+}
+
+// inLocation returns the time with the same wall clock in the timezone,
+// as floating times are parsed in the local timezone.
+func inLocation(t time.Time, loc *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+func isAllDayEvent(evnt *gocal.Event) bool {
+	if evnt.RawStart.Params["VALUE"] == "DATE" {
+		return true
+	}
+
+	var s time.Time
+	if evnt.Start != nil {
+		s = *evnt.Start
+	}
+
+	var e time.Time
+	if evnt.End != nil {
+		e = *evnt.End
+	}
+
+	return e.Sub(s) == 24*time.Hour && s.Hour() == 0 && s.Minute() == 0
+}
+
+// Sort sorts events by their start time.
+func Sort(events []Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Start.Before(events[j].Start)
+	})
+}
+
+// Limit returns at most n events. A non-positive n means no limit.
+func Limit(events []Event, n int) []Event {
+	if n > 0 && len(events) > n {
+		return events[:n]
+	}
+	return events
+}
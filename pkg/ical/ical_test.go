@@ -0,0 +1,346 @@
+package ical
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCancellations(t *testing.T) {
+	const (
+		googleSeries = "3k9d8f7g6h5j4k3l2m1n0p9q8r@google.com"
+		googleSingle = "7a6b5c4d3e2f1g0h9i8j7k6l5m@google.com"
+		outlookDaily = "040000008200E00074C5B7101A82E0080000000010A1B2C3D4E5F601000000000000000010000000A1B2C3D4E5F60718293A4B5C6D7E8F"
+		outlookOnce  = "040000008200E00074C5B7101A82E00800000000F0E1D2C3B4A5960100000000000000001000000096A5B4C3D2E1F00F1E2D3C4B5A69788"
+		outlookMeet  = "040000008200E00074C5B7101A82E00800000000AB12CD34EF56780100000000000000001000000012AB34CD56EF7890AB12CD34EF567890"
+	)
+
+	tests := []struct {
+		name          string
+		file          string
+		wantEvents    []string
+		wantCancelled []string
+	}{
+		{
+			name: "google cancelled instance and event",
+			file: "google-cancelled.ics",
+			wantEvents: []string{
+				"Swimming lessons 2024-06-03",
+				"Parents evening 2024-06-06",
+			},
+			wantCancelled: []string{
+				googleSeries + "/20240605",
+				googleSingle,
+			},
+		},
+		{
+			name: "outlook cancelled occurrence and meeting",
+			file: "outlook-cancelled.ics",
+			wantEvents: []string{
+				"Team stand-up 2024-06-03",
+				"Team stand-up 2024-06-05",
+				"Team stand-up 2024-06-06",
+				"Team stand-up 2024-06-07",
+			},
+			wantCancelled: []string{
+				outlookDaily + "/20240604",
+				outlookOnce,
+			},
+		},
+		{
+			name:          "outlook cancel method",
+			file:          "outlook-cancel-method.ics",
+			wantEvents:    []string{},
+			wantCancelled: []string{outlookMeet},
+		},
+	}
+
+	start := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := os.Open("testdata/" + test.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			cal, err := Parse(f, start, end)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			events := []string{}
+			for _, evnt := range cal.Events {
+				events = append(events, evnt.Summary+" "+evnt.Start.Format(time.DateOnly))
+			}
+			if !slices.Equal(events, test.wantEvents) {
+				t.Errorf("got events %q, want %q", events, test.wantEvents)
+			}
+
+			// Instances are compared by day, as the ids hold their start
+			// time in UTC.
+			cancelled := []string{}
+			for _, id := range cal.Cancelled {
+				if uid, at, ok := strings.Cut(id, "/"); ok {
+					id = uid + "/" + at[:len("20060102")]
+				}
+				cancelled = append(cancelled, id)
+			}
+			slices.Sort(cancelled)
+			want := slices.Clone(test.wantCancelled)
+			slices.Sort(want)
+			if !slices.Equal(cancelled, want) {
+				t.Errorf("got cancelled %q, want %q", cancelled, want)
+			}
+		})
+	}
+}
+
+func TestScan(t *testing.T) {
+	const feed = "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:Floating\r\n" +
+		"DESCRIPTION:END:VEVENT is text\\, not a component\r\n" +
+		"ATTENDEE;CN=Jane:mailto:jane@example.com\r\n" +
+		"uid:floating@example.com\r\n" +
+		"dtstart:20240603T090000\r\n" +
+		"BEGIN:VALARM\r\n" +
+		"ACTION:DISPLAY\r\n" +
+		"DESCRIPTION:Soon\r\n" +
+		"TRIGGER:-PT15M\r\n" +
+		"END:VALARM\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;TZID=Europe/London:20240603T100000\r\n" +
+		"LOCATION:Room 1\r\n" +
+		"DESCRIPTION:A folded line\r\n" +
+		" BEGIN:VALARM\r\n" +
+		"UID:zoned@example.com\r\n" +
+		"END:VEVENT\r\n" +
+		"X-WR-CALNAME:Work\r\n" +
+		"END:VCALENDAR\r\n"
+
+	props := scan([]byte(feed))
+
+	if props.name != "Work" {
+		t.Errorf("got name %q, want %q", props.name, "Work")
+	}
+	if want := map[string]bool{"floating@example.com": true}; !maps.Equal(props.floating, want) {
+		t.Errorf("got floating %v, want %v", props.floating, want)
+	}
+	want := map[string][]Alarm{
+		"floating@example.com": {{Action: "DISPLAY", Description: "Soon", Offset: -15 * time.Minute}},
+	}
+	if !maps.EqualFunc(props.alarms, want, slices.Equal[[]Alarm]) {
+		t.Errorf("got alarms %v, want %v", props.alarms, want)
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	feed := largeFeed(2000)
+	start := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(feed)))
+	b.ResetTimer()
+	for range b.N {
+		if _, err := Parse(bytes.NewReader(feed), start, end); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// largeFeed returns a feed of n events in the style of a busy shared
+// calendar, with attendees, alarms, long descriptions and every tenth
+// event recurring weekly.
+func largeFeed(n int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//glasslabs//calendar benchmark//EN\r\nX-WR-CALNAME:Team\r\n")
+	start := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	for i := range n {
+		at := start.Add(time.Duration(i) * 37 * time.Minute)
+		fmt.Fprintf(&buf, "BEGIN:VEVENT\r\nUID:event-%d@example.com\r\nDTSTAMP:20240501T000000Z\r\n", i)
+		fmt.Fprintf(&buf, "DTSTART:%s\r\nDTEND:%s\r\n", at.Format("20060102T150405Z"), at.Add(30*time.Minute).Format("20060102T150405Z"))
+		if i%10 == 0 {
+			buf.WriteString("RRULE:FREQ=WEEKLY;COUNT=20\r\n")
+		}
+		fmt.Fprintf(&buf, "SUMMARY:Meeting %d\r\nLOCATION:Room %d\r\n", i, i%12)
+		buf.WriteString("DESCRIPTION:" + strings.Repeat("Agenda item\\, notes and links to documents. ", 4) + "\r\n " + strings.Repeat("More notes. ", 6) + "\r\n")
+		for j := range 3 {
+			fmt.Fprintf(&buf, "ATTENDEE;CN=Person %d;PARTSTAT=ACCEPTED:mailto:person%d@example.com\r\n", j, j)
+		}
+		buf.WriteString("BEGIN:VALARM\r\nACTION:DISPLAY\r\nDESCRIPTION:Reminder\r\nTRIGGER:-PT10M\r\nEND:VALARM\r\n")
+		buf.WriteString("END:VEVENT\r\n")
+	}
+	buf.WriteString("END:VCALENDAR\r\n")
+	return buf.Bytes()
+}
+
+func TestParseTasks(t *testing.T) {
+	const feed = "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VTODO\r\nUID:dated\r\nSUMMARY:Pay rent\r\nDUE;VALUE=DATE:20240604\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nUID:zoned\r\nSUMMARY:Call plumber\r\nDUE;TZID=Europe/London:20240603T170000\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nUID:floating\r\nSUMMARY:Water plants\r\nDUE:20240605T090000\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nUID:completed\r\nSUMMARY:Done\r\nDUE:20240603T100000Z\r\nSTATUS:COMPLETED\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nUID:undated\r\nSUMMARY:Someday\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nUID:later\r\nSUMMARY:Later\r\nDUE:20240701T100000Z\r\nEND:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	start := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cal, err := ParseInLocation(strings.NewReader(feed), start, end, berlin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := []string{}
+	for _, evnt := range cal.Events {
+		if evnt.Kind != KindTask {
+			t.Errorf("got kind %q for %s, want %q", evnt.Kind, evnt.UID, KindTask)
+		}
+		got = append(got, evnt.UID+" "+evnt.Start.UTC().Format(time.RFC3339)+" "+strconv.FormatBool(evnt.AllDay))
+	}
+	want := []string{
+		"zoned 2024-06-03T16:00:00Z false",
+		"dated 2024-06-04T00:00:00Z true",
+		"floating 2024-06-05T07:00:00Z false",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got tasks %q, want %q", got, want)
+	}
+}
+
+func TestCompleteTask(t *testing.T) {
+	const obj = "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:rent\r\n" +
+		"DTSTAMP:20240501T000000Z\r\n" +
+		"SUMMARY:Pay the rent before the end of the day\\, as the landlord asked in th\r\n" +
+		" e last letter\r\n" +
+		"DUE;VALUE=DATE:20240604\r\n" +
+		"STATUS:NEEDS-ACTION\r\n" +
+		"BEGIN:VALARM\r\n" +
+		"ACTION:DISPLAY\r\n" +
+		"DESCRIPTION:Rent\r\n" +
+		"TRIGGER:-PT1H\r\n" +
+		"END:VALARM\r\n" +
+		"END:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	got, err := CompleteTask([]byte(obj), time.Date(2024, 6, 3, 9, 30, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const want = "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:rent\r\n" +
+		"SUMMARY:Pay the rent before the end of the day\\, as the landlord asked in t\r\n" +
+		" he last letter\r\n" +
+		"DUE;VALUE=DATE:20240604\r\n" +
+		"BEGIN:VALARM\r\n" +
+		"ACTION:DISPLAY\r\n" +
+		"DESCRIPTION:Rent\r\n" +
+		"TRIGGER:-PT1H\r\n" +
+		"END:VALARM\r\n" +
+		"STATUS:COMPLETED\r\n" +
+		"COMPLETED:20240603T093000Z\r\n" +
+		"PERCENT-COMPLETE:100\r\n" +
+		"LAST-MODIFIED:20240603T093000Z\r\n" +
+		"DTSTAMP:20240603T093000Z\r\n" +
+		"END:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	recurring := strings.Replace(obj, "STATUS:NEEDS-ACTION", "RRULE:FREQ=MONTHLY", 1)
+	if _, err = CompleteTask([]byte(recurring), time.Now()); err == nil {
+		t.Error("got no error completing a recurring to-do")
+	}
+}
+
+func TestCalDAVChecksFinalURL(t *testing.T) {
+	// The client follows redirects without consulting the fetcher, as
+	// the browser fetch API does.
+	other := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusMultiStatus)
+		_, _ = rw.Write([]byte(`<d:multistatus xmlns:d="DAV:"/>`))
+	}))
+	defer other.Close()
+	otherURL, err := url.Parse(other.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		http.Redirect(rw, req, "http://localhost:"+otherURL.Port()+"/", http.StatusMovedPermanently)
+	}))
+	defer srv.Close()
+
+	f := NewFetcher()
+	f.Client = &http.Client{}
+	f.AllowedHosts = []string{"127.0.0.1"}
+	dav := &CalDAV{Fetcher: f, URL: srv.URL}
+
+	_, err = dav.Calendars(context.Background())
+	if err == nil || !strings.Contains(err.Error(), `host "localhost" is not allowed`) {
+		t.Errorf("got error %v, want the redirect refused", err)
+	}
+}
+
+func TestDescribeRule(t *testing.T) {
+	tests := []struct {
+		name string
+		rule map[string]string
+		want string
+	}{
+		{name: "daily", rule: map[string]string{"FREQ": "DAILY"}, want: "daily"},
+		{name: "every other day", rule: map[string]string{"FREQ": "DAILY", "INTERVAL": "2"}, want: "every 2 days"},
+		{name: "weekly", rule: map[string]string{"FREQ": "WEEKLY"}, want: "weekly"},
+		{name: "weekly on a day", rule: map[string]string{"FREQ": "WEEKLY", "BYDAY": "TU"}, want: "every Tuesday"},
+		{name: "weekdays", rule: map[string]string{"FREQ": "WEEKLY", "BYDAY": "MO,TU,WE,TH,FR"}, want: "every weekday"},
+		{
+			name: "fortnightly on days",
+			rule: map[string]string{"FREQ": "WEEKLY", "INTERVAL": "2", "BYDAY": "MO,WE,FR"},
+			want: "every 2 weeks on Monday, Wednesday and Friday",
+		},
+		{name: "monthly", rule: map[string]string{"FREQ": "MONTHLY"}, want: "monthly"},
+		{name: "monthly on a day", rule: map[string]string{"FREQ": "MONTHLY", "BYMONTHDAY": "1,12,22,23"}, want: "monthly on the 1st, 12th, 22nd and 23rd"},
+		{name: "last day", rule: map[string]string{"FREQ": "MONTHLY", "BYMONTHDAY": "-1"}, want: "monthly on the last day"},
+		{name: "second to last day", rule: map[string]string{"FREQ": "MONTHLY", "BYMONTHDAY": "-2"}, want: "monthly on the 2nd to last day"},
+		{name: "third to last day", rule: map[string]string{"FREQ": "MONTHLY", "BYMONTHDAY": "-3"}, want: "monthly on the 3rd to last day"},
+		{name: "invalid day", rule: map[string]string{"FREQ": "MONTHLY", "BYMONTHDAY": "0,x"}, want: "monthly"},
+		{name: "first weekday", rule: map[string]string{"FREQ": "MONTHLY", "BYDAY": "1TU"}, want: "monthly on the first Tuesday"},
+		{name: "last weekday", rule: map[string]string{"FREQ": "MONTHLY", "BYDAY": "-1FR"}, want: "monthly on the last Friday"},
+		{name: "second to last weekday", rule: map[string]string{"FREQ": "MONTHLY", "BYDAY": "-2FR"}, want: "monthly on the second to last Friday"},
+		{name: "third to last weekday", rule: map[string]string{"FREQ": "MONTHLY", "BYDAY": "-3SU"}, want: "monthly on the third to last Sunday"},
+		{name: "yearly", rule: map[string]string{"FREQ": "YEARLY", "BYMONTH": "6", "BYMONTHDAY": "3"}, want: "yearly on June 3"},
+		{name: "count", rule: map[string]string{"FREQ": "DAILY", "COUNT": "5"}, want: "daily, 5 times"},
+		{name: "until", rule: map[string]string{"FREQ": "WEEKLY", "UNTIL": "20241231T235959Z"}, want: "weekly, until Dec 31, 2024"},
+		{name: "unknown", rule: map[string]string{"FREQ": "HOURLY"}, want: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := DescribeRule(test.rule); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
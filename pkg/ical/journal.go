@@ -0,0 +1,72 @@
+package ical
+
+import (
+	"strings"
+	"time"
+)
+
+// KindNote is the kind of the events of journal entries.
+const KindNote = "note"
+
+// journal is a VJOURNAL component.
+type journal struct {
+	uid         string
+	summary     string
+	description string
+	date        time.Time
+	cancelled   bool
+}
+
+// set sets the journal property.
+func (j *journal) set(name, value string) {
+	switch name {
+	case "UID":
+		j.uid = value
+	case "SUMMARY":
+		j.summary = value
+	case "DESCRIPTION":
+		j.description = value
+	case "STATUS":
+		j.cancelled = strings.EqualFold(value, "CANCELLED")
+	case "DTSTART":
+		// Journal entries are dated notes, so only the date is kept.
+		if len(value) < len("20060102") {
+			return
+		}
+		d, err := time.Parse("20060102", value[:len("20060102")])
+		if err != nil {
+			return
+		}
+		j.date = d
+	}
+}
+
+// noteEvents returns the all day events of the journal entries dated
+// within the window between start and end.
+func noteEvents(journals []journal, start, end time.Time) []Event {
+	var evnts []Event
+	for _, j := range journals {
+		if j.cancelled || j.date.IsZero() {
+			continue
+		}
+		dayEnd := j.date.Add(24 * time.Hour)
+		if !j.date.Before(end) || !dayEnd.After(start) {
+			continue
+		}
+
+		summary := j.summary
+		if summary == "" {
+			summary, _, _ = strings.Cut(j.description, "\n")
+		}
+		evnts = append(evnts, Event{
+			Kind:        KindNote,
+			UID:         j.uid,
+			Summary:     summary,
+			Description: j.description,
+			Start:       j.date,
+			End:         dayEnd,
+			AllDay:      true,
+		})
+	}
+	return evnts
+}
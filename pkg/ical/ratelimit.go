@@ -0,0 +1,59 @@
+package ical
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// HostLimiter limits the rate of requests made to each host.
+type HostLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+// NewHostLimiter returns a limiter allowing qps requests per second
+// to each host. A nil limiter is returned if qps is not positive.
+func NewHostLimiter(qps float64) *HostLimiter {
+	if qps <= 0 {
+		return nil
+	}
+
+	return &HostLimiter{
+		interval: time.Duration(float64(time.Second) / qps),
+		next:     map[string]time.Time{},
+	}
+}
+
+// Wait blocks until a request to the host is allowed or the context is done.
+func (l *HostLimiter) Wait(ctx context.Context, host string) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	t := l.next[host]
+	if t.Before(now) {
+		t = now
+	}
+	l.next[host] = t.Add(l.interval)
+	l.mu.Unlock()
+
+	d := t.Sub(now)
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
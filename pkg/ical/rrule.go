@@ -0,0 +1,191 @@
+package ical
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+var rruleDays = map[string]string{
+	"MO": "Monday", "TU": "Tuesday", "WE": "Wednesday", "TH": "Thursday",
+	"FR": "Friday", "SA": "Saturday", "SU": "Sunday",
+}
+
+var rruleOrdinals = map[int]string{
+	1: "first", 2: "second", 3: "third", 4: "fourth", 5: "fifth",
+}
+
+// DescribeRule returns a human readable description of a recurrence rule,
+// e.g. "every Tuesday" or "monthly on the 1st". It returns an empty
+// string if the rule cannot be described.
+func DescribeRule(rule map[string]string) string {
+	interval, _ := strconv.Atoi(rule["INTERVAL"])
+	if interval < 1 {
+		interval = 1
+	}
+
+	var s string
+	switch rule["FREQ"] {
+	case "DAILY":
+		s = every(interval, "day", "daily")
+	case "WEEKLY":
+		days := splitRule(rule["BYDAY"])
+		switch {
+		case len(days) == 0:
+			s = every(interval, "week", "weekly")
+		case interval == 1 && isWeekdays(days):
+			s = "every weekday"
+		case interval == 1:
+			s = "every " + dayList(days)
+		default:
+			s = every(interval, "week", "") + " on " + dayList(days)
+		}
+	case "MONTHLY":
+		s = every(interval, "month", "monthly")
+		if on := monthlyOn(rule); on != "" {
+			s += " on the " + on
+		}
+	case "YEARLY":
+		s = every(interval, "year", "yearly")
+		month, _ := strconv.Atoi(rule["BYMONTH"])
+		day, _ := strconv.Atoi(rule["BYMONTHDAY"])
+		if month >= 1 && month <= 12 && day >= 1 {
+			s += " on " + time.Month(month).String() + " " + strconv.Itoa(day)
+		}
+	default:
+		return ""
+	}
+
+	if count, err := strconv.Atoi(rule["COUNT"]); err == nil && count > 0 {
+		s += ", " + strconv.Itoa(count) + " times"
+	}
+	if until := parseUntil(rule["UNTIL"]); !until.IsZero() {
+		s += ", until " + until.Format("Jan 2, 2006")
+	}
+	return s
+}
+
+func every(n int, unit, single string) string {
+	switch {
+	case n == 1 && single != "":
+		return single
+	case n == 1:
+		return "every " + unit
+	default:
+		return "every " + strconv.Itoa(n) + " " + unit + "s"
+	}
+}
+
+func splitRule(v string) []string {
+	if v == "" {
+		return nil
+	}
+	return strings.Split(v, ",")
+}
+
+func isWeekdays(days []string) bool {
+	if len(days) != 5 {
+		return false
+	}
+	for _, d := range days {
+		if d == "SA" || d == "SU" || rruleDays[d] == "" {
+			return false
+		}
+	}
+	return true
+}
+
+func dayList(days []string) string {
+	names := make([]string, 0, len(days))
+	for _, d := range days {
+		if name, ok := rruleDays[d]; ok {
+			names = append(names, name)
+		}
+	}
+	return joinList(names)
+}
+
+func joinList(items []string) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	default:
+		return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+	}
+}
+
+// monthlyOn describes the days of a monthly rule, e.g. "1st" or "first Tuesday".
+func monthlyOn(rule map[string]string) string {
+	var on []string
+	for _, v := range splitRule(rule["BYMONTHDAY"]) {
+		n, err := strconv.Atoi(v)
+		switch {
+		case err != nil || n == 0:
+		case n == -1:
+			on = append(on, "last day")
+		case n < 0:
+			on = append(on, ordinal(-n)+" to last day")
+		default:
+			on = append(on, ordinal(n))
+		}
+	}
+	for _, v := range splitRule(rule["BYDAY"]) {
+		if len(v) < 2 {
+			continue
+		}
+		day, ok := rruleDays[v[len(v)-2:]]
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(v[:len(v)-2])
+		if err != nil {
+			on = append(on, day)
+			continue
+		}
+		if o := dayOrdinal(n); o != "" {
+			on = append(on, o+" "+day)
+		}
+	}
+	return joinList(on)
+}
+
+// dayOrdinal returns the ordinal of a weekday in the month, counting from
+// the end for negative n, e.g. "second" or "second to last".
+func dayOrdinal(n int) string {
+	switch {
+	case n == -1:
+		return "last"
+	case n < 0:
+		if o := rruleOrdinals[-n]; o != "" {
+			return o + " to last"
+		}
+		return ""
+	default:
+		return rruleOrdinals[n]
+	}
+}
+
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+func parseUntil(v string) time.Time {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
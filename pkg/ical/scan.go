@@ -0,0 +1,189 @@
+package ical
+
+import (
+	"strings"
+	"time"
+)
+
+// properties are the calendar properties not kept by the event parser.
+type properties struct {
+	name     string
+	timezone string
+	alarms   map[string][]Alarm
+	floating map[string]bool
+	busy     []period
+	journals []journal
+	todos    []todo
+}
+
+// scan reads the calendar properties, busy periods, journal entries and
+// to-dos, and the alarms of the events in the calendar and whether they start at
+// a floating time by UID.
+func scan(b []byte) properties {
+	props := properties{alarms: map[string][]Alarm{}, floating: map[string]bool{}}
+
+	var (
+		stack    []string
+		uid      string
+		evnt     []Alarm
+		alarm    Alarm
+		hasTrg   bool
+		floating bool
+		jrnl     journal
+		td       todo
+	)
+	for _, line := range unfoldLines(b) {
+		// Most lines are event properties not read here, which are
+		// skipped without parsing them.
+		if n := len(stack); n > 0 && stack[n-1] == "VEVENT" {
+			switch propertyName(line) {
+			case "BEGIN", "END", "UID", "DTSTART":
+			default:
+				continue
+			}
+		}
+
+		name, params, value := parseProperty(line)
+		switch name {
+		case "BEGIN":
+			stack = append(stack, strings.ToUpper(value))
+			switch strings.ToUpper(value) {
+			case "VEVENT":
+				uid, evnt, floating = "", nil, false
+			case "VALARM":
+				alarm, hasTrg = Alarm{}, false
+			case "VJOURNAL":
+				jrnl = journal{}
+			case "VTODO":
+				td = todo{}
+			}
+			continue
+		case "END":
+			if len(stack) == 0 {
+				continue
+			}
+			switch stack[len(stack)-1] {
+			case "VEVENT":
+				if _, ok := props.alarms[uid]; !ok && len(evnt) > 0 {
+					props.alarms[uid] = evnt
+				}
+				if floating {
+					props.floating[uid] = true
+				}
+			case "VALARM":
+				if hasTrg {
+					evnt = append(evnt, alarm)
+				}
+			case "VJOURNAL":
+				props.journals = append(props.journals, jrnl)
+			case "VTODO":
+				props.todos = append(props.todos, td)
+			}
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		if len(stack) == 0 {
+			continue
+		}
+
+		switch stack[len(stack)-1] {
+		case "VCALENDAR":
+			switch name {
+			case "X-WR-CALNAME":
+				props.name = value
+			case "X-WR-TIMEZONE":
+				props.timezone = value
+			}
+		case "VEVENT":
+			switch name {
+			case "UID":
+				uid = value
+			case "DTSTART":
+				floating = params["TZID"] == "" && params["VALUE"] != "DATE" && len(value) == len("20060102T150405")
+			}
+		case "VJOURNAL":
+			jrnl.set(name, value)
+		case "VTODO":
+			td.set(line, name, params, value)
+		case "VFREEBUSY":
+			if name == "FREEBUSY" {
+				props.busy = append(props.busy, parsePeriods(value, params)...)
+			}
+		case "VALARM":
+			switch name {
+			case "ACTION":
+				alarm.Action = strings.ToUpper(value)
+			case "DESCRIPTION":
+				alarm.Description = value
+			case "TRIGGER":
+				if params["VALUE"] == "DATE-TIME" {
+					at, err := time.Parse("20060102T150405Z", value)
+					if err != nil {
+						continue
+					}
+					alarm.At = at
+				} else {
+					d, err := parseDuration(value)
+					if err != nil {
+						continue
+					}
+					alarm.Offset = d
+					alarm.RelatedEnd = params["RELATED"] == "END"
+				}
+				hasTrg = true
+			}
+		}
+	}
+	return props
+}
+
+// unfoldLines splits the calendar into its unfolded content lines.
+func unfoldLines(b []byte) []string {
+	// Lines are sliced from a single copy of the calendar.
+	s := string(b)
+	lines := make([]string, 0, strings.Count(s, "\n")+1)
+	for s != "" {
+		var line string
+		line, s, _ = strings.Cut(s, "\n")
+		line = strings.TrimRight(line, "\r")
+		if n := len(lines); n > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[n-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// propertyName returns the upper case name of a content line.
+func propertyName(line string) string {
+	if i := strings.IndexAny(line, ";:"); i >= 0 {
+		line = line[:i]
+	}
+	return strings.ToUpper(line)
+}
+
+// parseProperty splits a content line into its upper case name, its
+// parameters and its unescaped value.
+func parseProperty(line string) (string, map[string]string, string) {
+	head, value, _ := strings.Cut(line, ":")
+	parts := strings.Split(head, ";")
+
+	var params map[string]string
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		if params == nil {
+			params = map[string]string{}
+		}
+		params[strings.ToUpper(k)] = strings.ToUpper(strings.Trim(v, `"`))
+	}
+	return strings.ToUpper(parts[0]), params, unescapeText(value)
+}
+
+// unescapeText unescapes an iCalendar text value.
+func unescapeText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
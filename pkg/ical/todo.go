@@ -0,0 +1,165 @@
+package ical
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strings"
+	"time"
+)
+
+// KindTask is the kind of the events of to-dos.
+const KindTask = "task"
+
+// todo is a VTODO component.
+type todo struct {
+	uid         string
+	summary     string
+	description string
+	due         string
+	dueTZID     string
+	dueIsDate   bool
+	recurring   bool
+	done        bool
+}
+
+// set sets the to-do property of the content line.
+func (t *todo) set(line, name string, params map[string]string, value string) {
+	switch name {
+	case "UID":
+		t.uid = value
+	case "SUMMARY":
+		t.summary = value
+	case "DESCRIPTION":
+		t.description = value
+	case "DUE":
+		t.due = value
+		t.dueTZID = rawParam(line, "TZID")
+		t.dueIsDate = params["VALUE"] == "DATE" || len(value) == len(dateFormat)
+	case "RRULE":
+		t.recurring = true
+	case "STATUS":
+		t.done = t.done || strings.EqualFold(value, "COMPLETED") || strings.EqualFold(value, "CANCELLED")
+	case "COMPLETED":
+		t.done = true
+	}
+}
+
+// dueTime returns the due time of the to-do, with floating times in loc.
+func (t *todo) dueTime(loc *time.Location) (time.Time, error) {
+	switch {
+	case t.dueIsDate:
+		return time.Parse(dateFormat, t.due)
+	case strings.HasSuffix(t.due, "Z"):
+		return time.Parse(dateTimeFormat, t.due)
+	}
+	if t.dueTZID != "" {
+		// Unknown timezones are treated as floating, as they are
+		// for events.
+		if tz, err := time.LoadLocation(t.dueTZID); err == nil {
+			loc = tz
+		}
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	return time.ParseInLocation("20060102T150405", t.due, loc)
+}
+
+// taskEvents returns the events of the open to-dos due within the window
+// between start and end. To-dos without a due date are not shown.
+func taskEvents(todos []todo, loc *time.Location, start, end time.Time) []Event {
+	var evnts []Event
+	for _, t := range todos {
+		if t.done || t.due == "" {
+			continue
+		}
+		due, err := t.dueTime(loc)
+		if err != nil {
+			continue
+		}
+		dueEnd := due
+		if t.dueIsDate {
+			dueEnd = due.Add(24 * time.Hour)
+		}
+		if dueEnd.Before(start) || !due.Before(end) {
+			continue
+		}
+
+		evnts = append(evnts, Event{
+			Kind:        KindTask,
+			UID:         t.uid,
+			Summary:     t.summary,
+			Description: t.description,
+			Start:       due,
+			End:         dueEnd,
+			AllDay:      t.dueIsDate,
+			IsRecurring: t.recurring,
+		})
+	}
+	return evnts
+}
+
+// CompleteTask returns the calendar object with its to-do marked
+// completed at the given time, keeping all other properties.
+// Recurring to-dos are refused, as completing them would complete
+// every instance.
+func CompleteTask(obj []byte, at time.Time) ([]byte, error) {
+	stamp := at.UTC().Format(dateTimeFormat)
+
+	var (
+		buf   bytes.Buffer
+		stack []string
+		found bool
+	)
+	bw := bufio.NewWriter(&buf)
+	for _, line := range unfoldLines(obj) {
+		name, _, value := parseProperty(line)
+		inTodo := len(stack) > 0 && stack[len(stack)-1] == "VTODO"
+		switch {
+		case name == "BEGIN":
+			stack = append(stack, strings.ToUpper(value))
+		case name == "END" && inTodo:
+			if found {
+				return nil, errors.New("calendar object has more than one to-do")
+			}
+			found = true
+			writeLine(bw, "STATUS:COMPLETED")
+			writeLine(bw, "COMPLETED:"+stamp)
+			writeLine(bw, "PERCENT-COMPLETE:100")
+			writeLine(bw, "LAST-MODIFIED:"+stamp)
+			writeLine(bw, "DTSTAMP:"+stamp)
+			stack = stack[:len(stack)-1]
+		case name == "END":
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case inTodo && name == "RRULE":
+			return nil, errors.New("recurring to-dos cannot be completed")
+		case inTodo && (name == "STATUS" || name == "COMPLETED" || name == "PERCENT-COMPLETE" ||
+			name == "LAST-MODIFIED" || name == "DTSTAMP"):
+			continue
+		}
+		writeLine(bw, line)
+	}
+	if !found {
+		return nil, errors.New("calendar object has no to-do")
+	}
+	if err := bw.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// rawParam returns the value of the named parameter of the content line
+// in its original case, e.g. the TZID naming a timezone.
+func rawParam(line, name string) string {
+	head, _, _ := strings.Cut(line, ":")
+	parts := strings.Split(head, ";")
+	for _, p := range parts[1:] {
+		if k, v, ok := strings.Cut(p, "="); ok && strings.EqualFold(k, name) {
+			return strings.Trim(v, `"`)
+		}
+	}
+	return ""
+}
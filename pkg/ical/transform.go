@@ -0,0 +1,110 @@
+package ical
+
+import (
+	"regexp"
+	"time"
+)
+
+// EventTransformer transforms events between parsing and rendering,
+// filtering, rewriting or enriching them.
+type EventTransformer interface {
+	Transform(events []Event) []Event
+}
+
+// TransformerFunc is a function that transforms events.
+type TransformerFunc func(events []Event) []Event
+
+// Transform transforms the events.
+func (fn TransformerFunc) Transform(events []Event) []Event {
+	return fn(events)
+}
+
+// Chain applies each transformer in order.
+type Chain []EventTransformer
+
+// Transform transforms the events with each transformer in the chain.
+func (c Chain) Transform(events []Event) []Event {
+	for _, t := range c {
+		events = t.Transform(events)
+	}
+	return events
+}
+
+// Exclude returns a transformer that removes events with a summary matching re.
+func Exclude(re *regexp.Regexp) EventTransformer {
+	return TransformerFunc(func(events []Event) []Event {
+		res := events[:0]
+		for _, evnt := range events {
+			if !re.MatchString(evnt.Summary) {
+				res = append(res, evnt)
+			}
+		}
+		return res
+	})
+}
+
+// Include returns a transformer that keeps only events with a summary matching re.
+func Include(re *regexp.Regexp) EventTransformer {
+	return TransformerFunc(func(events []Event) []Event {
+		res := events[:0]
+		for _, evnt := range events {
+			if re.MatchString(evnt.Summary) {
+				res = append(res, evnt)
+			}
+		}
+		return res
+	})
+}
+
+// Rewrite returns a transformer that replaces matches of re in event
+// summaries with repl, which may reference submatches as in regexp.Expand.
+func Rewrite(re *regexp.Regexp, repl string) EventTransformer {
+	return TransformerFunc(func(events []Event) []Event {
+		for i := range events {
+			events[i].Summary = re.ReplaceAllString(events[i].Summary, repl)
+		}
+		return events
+	})
+}
+
+// Dedup returns a transformer that removes events with the same summary
+// and start as an earlier event, as happens when calendars share events.
+func Dedup() EventTransformer {
+	return TransformerFunc(func(events []Event) []Event {
+		type key struct {
+			summary string
+			start   time.Time
+		}
+
+		seen := make(map[key]struct{}, len(events))
+		res := events[:0]
+		for _, evnt := range events {
+			k := key{summary: evnt.Summary, start: evnt.Start.UTC()}
+			if _, ok := seen[k]; ok {
+				continue
+			}
+			seen[k] = struct{}{}
+			res = append(res, evnt)
+		}
+		return res
+	})
+}
+
+// CollapseRecurring returns a transformer that keeps only the first
+// instance of each recurring series, matched by UID.
+func CollapseRecurring() EventTransformer {
+	return TransformerFunc(func(events []Event) []Event {
+		seen := make(map[string]struct{}, len(events))
+		res := events[:0]
+		for _, evnt := range events {
+			if evnt.IsRecurring && evnt.UID != "" {
+				if _, ok := seen[evnt.UID]; ok {
+					continue
+				}
+				seen[evnt.UID] = struct{}{}
+			}
+			res = append(res, evnt)
+		}
+		return res
+	})
+}
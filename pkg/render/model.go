@@ -0,0 +1,197 @@
+package render
+
+import "time"
+
+// Model is the view model of the module.
+type Model struct {
+	Now     time.Time
+	View    string
+	Profile string
+	Lang    string
+	Dir     string
+	Events  []Event
+	More    int
+	Errors  []FetchError
+	Status  []SourceStatus
+	Quiet   string
+	Next    *Event
+
+	// Reminders are the triggered event alarms not yet acknowledged.
+	Reminders []Reminder
+
+	// Pages marks the current page of the rotating views, and is
+	// empty when views are not rotated.
+	Pages         []bool
+	Page          int
+	PageIndicator string
+
+	Timeline *Timeline
+	Ribbon   *Ribbon
+	Room     *Room
+	FreeBusy *FreeBusyStrip
+}
+
+// Event contains event information.
+type Event struct {
+	ID          string
+	Icon        string
+	Color       string
+	Class       string
+	Kind        string
+	Title       string
+	Location    string
+	Distance    string
+	Description string
+	Attendees   []string
+	Recurrence  string
+	Time        time.Time
+	End         time.Time
+	AltDate     string
+	AltTime     string
+	IsAllDay    bool
+	IsToday     bool
+	Urgency     string
+	EndsIn      string
+	IsSeries    bool
+	IsNewWeek   bool
+	IsOffHours  bool
+	HasConflict bool
+	LeaveBy     time.Time
+	LeaveSoon   bool
+	IsPulsing   bool
+	IsExpanded  bool
+	CanComplete bool
+
+	// Alarms are the trigger times of the alarms of the event, and
+	// Chime is the time to chime before it starts.
+	Alarms []time.Time
+	Chime  time.Time
+
+	// Season, Episode and EpisodeTitle are set for events of media
+	// calendars that are TV episodes.
+	Season       int
+	Episode      int
+	EpisodeTitle string
+
+	// Course is set for events of LMS calendars.
+	Course string
+
+	// Props are the custom X- properties of the event by name.
+	Props map[string]string
+}
+
+// SourceStatus describes the fetch health of a calendar.
+type SourceStatus struct {
+	Calendar string
+	Status   string
+}
+
+// FetchError describes a calendar that could not be fetched.
+type FetchError struct {
+	Calendar string
+	Class    string
+}
+
+// Reminder is a triggered event alarm shown over the agenda.
+type Reminder struct {
+	Key         string
+	ID          string
+	Title       string
+	Description string
+	Time        time.Time
+	IsAllDay    bool
+
+	// Until is the time the reminder is hidden when not acknowledged.
+	Until time.Time
+}
+
+// Timeline is the view model of a day planner timeline of today.
+type Timeline struct {
+	AllDay []Event
+	Hours  []TimelineHour
+	Blocks []TimelineBlock
+
+	// Now is the position of the current time as a percentage of the
+	// timeline height, or negative when outside the timeline.
+	Now float64
+}
+
+// TimelineHour is an hour mark on the timeline.
+type TimelineHour struct {
+	Time time.Time
+	Top  float64
+}
+
+// TimelineBlock is an event positioned on the timeline, in percentages
+// of the timeline height and width.
+type TimelineBlock struct {
+	Event
+
+	Top    float64
+	Height float64
+	Left   float64
+	Width  float64
+}
+
+// Ribbon is the view model of a horizontal ribbon of the next hours.
+type Ribbon struct {
+	Hours  []RibbonHour
+	Blocks []RibbonBlock
+	Lanes  int
+
+	// Now is the position of the current time as a percentage of the
+	// ribbon width.
+	Now float64
+}
+
+// RibbonHour is an hour mark on the ribbon.
+type RibbonHour struct {
+	Time time.Time
+	Left float64
+}
+
+// RibbonBlock is an event positioned on the ribbon, in percentages of
+// the ribbon width, in a lane so overlapping events do not cover each other.
+type RibbonBlock struct {
+	Event
+
+	Left  float64
+	Width float64
+	Lane  int
+}
+
+// Room is the view model of the free/busy board of a meeting room.
+type Room struct {
+	Busy bool
+
+	// Current is the meeting in progress when busy.
+	Current *Event
+	// Until is the end of the back to back meetings when busy.
+	Until time.Time
+
+	// Next is the next meeting today when free.
+	Next *Event
+	// FreeMinutes is the number of minutes until the next meeting
+	// today, or zero when free for the rest of the day.
+	FreeMinutes int
+}
+
+// FreeBusyStrip is the view model of the combined availability of
+// a set of calendars over the next hours.
+type FreeBusyStrip struct {
+	Hours  []RibbonHour
+	Blocks []FreeBusyBlock
+
+	// Now is the position of the current time as a percentage of the
+	// strip width.
+	Now float64
+}
+
+// FreeBusyBlock is a busy period positioned on the strip, in
+// percentages of the strip width.
+type FreeBusyBlock struct {
+	Start time.Time
+	End   time.Time
+	Left  float64
+	Width float64
+}
@@ -0,0 +1,113 @@
+// Package render renders the view model of the calendar module to HTML.
+// It only depends on the view model, so external tools can render the
+// same output as the module, e.g. to check templates against golden files.
+package render
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"sync"
+	"time"
+)
+
+//go:embed templates/*.html
+var templates embed.FS
+
+// partialFiles are the files of the templates used by the main template.
+var partialFiles = []string{"agenda.html", "timeline.html", "ribbon.html", "room.html", "freebusy.html"}
+
+// Renderer renders a view model into the module contents.
+type Renderer interface {
+	Render(view Model) (string, error)
+}
+
+// HTMLRenderer renders a view model using an HTML template.
+type HTMLRenderer struct {
+	tmpl *template.Template
+}
+
+// templateFuncs are the functions available to the templates. They are
+// resolved once when the templates are parsed, rather than looking up
+// methods such as time.Time.Format by reflection on every render.
+var templateFuncs = template.FuncMap{
+	"format": func(t time.Time, layout string) string { return t.Format(layout) },
+}
+
+// NewHTMLRenderer returns an HTML renderer for the given template,
+// along with any partial templates it uses.
+func NewHTMLRenderer(text string, partials ...string) (*HTMLRenderer, error) {
+	tmpl, err := template.New("html").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing html: %w", err)
+	}
+	for _, partial := range partials {
+		if _, err = tmpl.New("").Parse(partial); err != nil {
+			return nil, fmt.Errorf("parsing html: %w", err)
+		}
+	}
+	return &HTMLRenderer{tmpl: tmpl}, nil
+}
+
+// Override replaces the named partial template, e.g. "event", with text.
+func (r *HTMLRenderer) Override(name, text string) error {
+	if r.tmpl.Lookup(name) == nil {
+		return fmt.Errorf("unknown template %q", name)
+	}
+	if _, err := r.tmpl.New(name).Parse(text); err != nil {
+		return fmt.Errorf("parsing %s template: %w", name, err)
+	}
+	return nil
+}
+
+// bufPool reuses render buffers, as renders happen every minute.
+var bufPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// Render renders the view model.
+func (r *HTMLRenderer) Render(view Model) (string, error) {
+	buf := bufPool.Get().(*bytes.Buffer)
+	defer func() {
+		buf.Reset()
+		bufPool.Put(buf)
+	}()
+
+	if err := r.tmpl.Execute(buf, view); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// New returns an HTML renderer for the embedded templates. Each renderer
+// parses the templates again, so its partials can be overridden without
+// affecting other renderers.
+func New() (*HTMLRenderer, error) {
+	text, err := templates.ReadFile("templates/index.html")
+	if err != nil {
+		return nil, err
+	}
+	parts := make([]string, 0, len(partialFiles))
+	for _, name := range partialFiles {
+		b, err := templates.ReadFile("templates/" + name)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, string(b))
+	}
+	return NewHTMLRenderer(string(text), parts...)
+}
+
+// defaultRenderer is the renderer of the embedded templates, which are
+// parsed once on first use.
+var defaultRenderer = sync.OnceValues(New)
+
+// Render renders the view model with the embedded templates.
+func Render(view Model) (string, error) {
+	r, err := defaultRenderer()
+	if err != nil {
+		return "", err
+	}
+	return r.Render(view)
+}
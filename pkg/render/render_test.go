@@ -0,0 +1,48 @@
+package render
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderOverride(t *testing.T) {
+	now := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	model := Model{
+		Now:  now,
+		View: "agenda",
+		Lang: "en",
+		Dir:  "ltr",
+		Events: []Event{
+			{ID: "standup", Title: "Standup", Time: now.Add(time.Hour), End: now.Add(90 * time.Minute)},
+		},
+	}
+
+	r, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Override("event", `<tr class="custom">{{ .Title }}</tr>`); err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.Render(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, `<tr class="custom">Standup</tr>`) {
+		t.Errorf("overridden event template not rendered:\n%s", got)
+	}
+
+	// The override does not change the shared renderer.
+	got, err = Render(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, `class="custom"`) || !strings.Contains(got, "Standup") {
+		t.Errorf("default templates not rendered:\n%s", got)
+	}
+
+	if err = r.Override("unknown", "text"); err == nil {
+		t.Error("overrode an unknown template")
+	}
+}
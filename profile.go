@@ -0,0 +1,168 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+// profile is a named set of calendars, filters and view.
+type profile struct {
+	name      string
+	calendars map[string]bool
+	exclude   []*regexp.Regexp
+	view      string
+
+	days       map[time.Weekday]bool
+	start, end time.Duration
+	scheduled  bool
+}
+
+func newProfiles(cfgs []Profile) ([]*profile, error) {
+	profs := make([]*profile, 0, len(cfgs))
+	names := map[string]bool{}
+	for i, cfg := range cfgs {
+		if cfg.Name == "" {
+			return nil, fmt.Errorf("profile %d: name is required", i)
+		}
+		if names[cfg.Name] {
+			return nil, fmt.Errorf("duplicate profile %q", cfg.Name)
+		}
+		names[cfg.Name] = true
+
+		prof, err := newProfile(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("profile %q: %w", cfg.Name, err)
+		}
+		profs = append(profs, prof)
+	}
+	return profs, nil
+}
+
+func newProfile(cfg Profile) (*profile, error) {
+	if cfg.View != "" {
+		if err := validateView(cfg.View); err != nil {
+			return nil, err
+		}
+	}
+
+	prof := &profile{name: cfg.Name, view: cfg.View}
+	if len(cfg.Calendars) > 0 {
+		prof.calendars = make(map[string]bool, len(cfg.Calendars))
+		for _, name := range cfg.Calendars {
+			prof.calendars[name] = true
+		}
+	}
+	for _, pattern := range cfg.Exclude {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("parsing exclude pattern: %w", err)
+		}
+		prof.exclude = append(prof.exclude, re)
+	}
+
+	if len(cfg.Days) == 0 && cfg.Start == "" && cfg.End == "" {
+		return prof, nil
+	}
+	prof.scheduled = true
+	if len(cfg.Days) > 0 {
+		prof.days = make(map[time.Weekday]bool, len(cfg.Days))
+		for _, s := range cfg.Days {
+			day, err := parseWeekday(s)
+			if err != nil {
+				return nil, err
+			}
+			prof.days[day] = true
+		}
+	}
+	if cfg.Start == "" && cfg.End == "" {
+		return prof, nil
+	}
+	if cfg.Start == "" || cfg.End == "" {
+		return nil, errors.New("start and end are both required")
+	}
+	var err error
+	if prof.start, err = parseTimeOfDay(cfg.Start); err != nil {
+		return nil, fmt.Errorf("parsing start: %w", err)
+	}
+	if prof.end, err = parseTimeOfDay(cfg.End); err != nil {
+		return nil, fmt.Errorf("parsing end: %w", err)
+	}
+	if prof.end == prof.start {
+		return nil, fmt.Errorf("end %s must differ from start %s", cfg.End, cfg.Start)
+	}
+	return prof, nil
+}
+
+// isScheduled reports whether the profile is scheduled at t. A time range
+// crossing midnight belongs to the day it starts on.
+func (p *profile) isScheduled(t time.Time) bool {
+	if !p.scheduled {
+		return false
+	}
+
+	day := t
+	if p.start != p.end {
+		afterStart, beforeEnd := !t.Before(atTimeOfDay(t, p.start)), t.Before(atTimeOfDay(t, p.end))
+		switch {
+		case p.start < p.end && !(afterStart && beforeEnd):
+			return false
+		case p.start > p.end && !afterStart && !beforeEnd:
+			return false
+		case p.start > p.end && !afterStart:
+			day = t.AddDate(0, 0, -1)
+		}
+	}
+	return p.days == nil || p.days[day.Weekday()]
+}
+
+// shows reports whether the event is shown in the profile. Events not
+// from a configured calendar, such as generated events, are only subject
+// to the exclude patterns.
+func (p *profile) shows(evnt ical.Event) bool {
+	if p.calendars != nil && evnt.Calendar != "" && !p.calendars[evnt.Calendar] {
+		return false
+	}
+	for _, re := range p.exclude {
+		if re.MatchString(evnt.Summary) {
+			return false
+		}
+	}
+	return true
+}
+
+// profile returns the active profile, which is the named profile if given,
+// otherwise the first profile scheduled at t. It returns nil when no
+// profile is active.
+func (p *pipeline) profile(name string, t time.Time) *profile {
+	if name != "" {
+		for _, prof := range p.profiles {
+			if prof.name == name {
+				return prof
+			}
+		}
+		return nil
+	}
+
+	t = t.In(p.tz)
+	for _, prof := range p.profiles {
+		if prof.isScheduled(t) {
+			return prof
+		}
+	}
+	return nil
+}
+
+// filter returns the events shown in the profile.
+func (p *profile) filter(evnts []ical.Event) []ical.Event {
+	res := evnts[:0]
+	for _, evnt := range evnts {
+		if p.shows(evnt) {
+			res = append(res, evnt)
+		}
+	}
+	return res
+}
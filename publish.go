@@ -0,0 +1,85 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// haSensorConfig is a Home Assistant MQTT discovery sensor configuration.
+type haSensorConfig struct {
+	Name                string `json:"name"`
+	UniqueID            string `json:"unique_id"`
+	ObjectID            string `json:"object_id"`
+	StateTopic          string `json:"state_topic"`
+	ValueTemplate       string `json:"value_template"`
+	JSONAttributesTopic string `json:"json_attributes_topic"`
+	Icon                string `json:"icon"`
+}
+
+// publishMQTT publishes the next event and today's agenda to the broker.
+func (m *Module) publishMQTT(ctx context.Context, msg eventsMessage) {
+	cfg := m.cfg.MQTT
+
+	m.mu.Lock()
+	discovered := m.discovered
+	m.mu.Unlock()
+
+	if cfg.HomeAssistant && !discovered {
+		if err := m.publishDiscovery(ctx); err != nil {
+			m.log.Error("Could not publish discovery config", "error", err.Error())
+			return
+		}
+
+		m.mu.Lock()
+		m.discovered = true
+		m.mu.Unlock()
+	}
+
+	var next any = struct{}{}
+	if msg.Next != nil {
+		next = msg.Next
+	}
+	b, err := json.Marshal(next)
+	if err != nil {
+		m.log.Error("Could not encode next event", "error", err.Error())
+		return
+	}
+	agenda, err := json.Marshal(msg.Today)
+	if err != nil {
+		m.log.Error("Could not encode agenda", "error", err.Error())
+		return
+	}
+
+	if err = m.mqtt.Publish(ctx, cfg.NextTopic, b, true); err != nil {
+		m.log.Error("Could not publish next event", "error", err.Error())
+		return
+	}
+	if err = m.mqtt.Publish(ctx, cfg.AgendaTopic, agenda, true); err != nil {
+		m.log.Error("Could not publish agenda", "error", err.Error())
+	}
+}
+
+// publishDiscovery publishes the Home Assistant discovery config for
+// the next event sensor, with the event details as its attributes.
+func (m *Module) publishDiscovery(ctx context.Context) error {
+	cfg := m.cfg.MQTT
+
+	b, err := json.Marshal(haSensorConfig{
+		Name:                "Mirror next event",
+		UniqueID:            "glasslabs_calendar_" + m.mod.Name() + "_" + cfg.ObjectID,
+		ObjectID:            cfg.ObjectID,
+		StateTopic:          cfg.NextTopic,
+		ValueTemplate:       "{{ value_json.title | default('') }}",
+		JSONAttributesTopic: cfg.NextTopic,
+		Icon:                "mdi:calendar",
+	})
+	if err != nil {
+		return fmt.Errorf("encoding discovery config: %w", err)
+	}
+
+	topic := cfg.DiscoveryPrefix + "/sensor/" + cfg.ObjectID + "/config"
+	return m.mqtt.Publish(ctx, topic, b, true)
+}
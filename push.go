@@ -0,0 +1,196 @@
+//go:build !js
+
+package main
+
+import (
+	"bufio"
+	"crypto/sha1" //nolint:gosec // Required by the WebSocket handshake.
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+// pushEvent is an event in a push message.
+type pushEvent struct {
+	ID       string    `json:"id"`
+	Title    string    `json:"title"`
+	Location string    `json:"location,omitempty"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	AllDay   bool      `json:"allDay"`
+}
+
+// pushDiff is the change in events pushed to clients.
+type pushDiff struct {
+	Added   []pushEvent `json:"added"`
+	Removed []string    `json:"removed"`
+}
+
+// pushHub pushes changes in the merged events to WebSocket clients.
+type pushHub struct {
+	mu      sync.Mutex
+	events  map[string]pushEvent
+	clients map[chan []byte]struct{}
+}
+
+func newPushHub() *pushHub {
+	return &pushHub{
+		events:  map[string]pushEvent{},
+		clients: map[chan []byte]struct{}{},
+	}
+}
+
+// Update replaces the events, pushing the difference to all clients.
+func (h *pushHub) Update(evnts []ical.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	next := make(map[string]pushEvent, len(evnts))
+	diff := pushDiff{Added: []pushEvent{}, Removed: []string{}}
+	for _, evnt := range evnts {
+		pe := pushEvent{
+			ID:       evnt.UID + "@" + evnt.Start.UTC().Format(time.RFC3339),
+			Title:    evnt.Summary,
+			Location: evnt.Location,
+			Start:    evnt.Start,
+			End:      evnt.End,
+			AllDay:   evnt.AllDay,
+		}
+		next[pe.ID] = pe
+
+		if prev, ok := h.events[pe.ID]; !ok || prev != pe {
+			diff.Added = append(diff.Added, pe)
+		}
+	}
+	for id := range h.events {
+		if _, ok := next[id]; !ok {
+			diff.Removed = append(diff.Removed, id)
+		}
+	}
+	h.events = next
+
+	if len(diff.Added) == 0 && len(diff.Removed) == 0 {
+		return
+	}
+	b, err := json.Marshal(diff)
+	if err != nil {
+		return
+	}
+	for ch := range h.clients {
+		select {
+		case ch <- b:
+		default:
+			// The client is too slow, drop it.
+			delete(h.clients, ch)
+			close(ch)
+		}
+	}
+}
+
+// ServeHTTP upgrades the connection to a WebSocket, sending all current
+// events followed by changes as they happen.
+func (h *pushHub) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	key := req.Header.Get("Sec-Websocket-Key")
+	if !strings.EqualFold(req.Header.Get("Upgrade"), "websocket") || key == "" {
+		http.Error(rw, "websocket upgrade required", http.StatusUpgradeRequired)
+		return
+	}
+
+	hj, ok := rw.(http.Hijacker)
+	if !ok {
+		http.Error(rw, "websocket not supported", http.StatusInternalServerError)
+		return
+	}
+	conn, brw, err := hj.Hijack()
+	if err != nil {
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	sum := sha1.Sum([]byte(key + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11")) //nolint:gosec // Required by the WebSocket handshake.
+	_, _ = brw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")
+	if err = brw.Flush(); err != nil {
+		return
+	}
+
+	ch := make(chan []byte, 16)
+	h.mu.Lock()
+	snapshot := pushDiff{Added: make([]pushEvent, 0, len(h.events)), Removed: []string{}}
+	for _, pe := range h.events {
+		snapshot.Added = append(snapshot.Added, pe)
+	}
+	h.clients[ch] = struct{}{}
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		if _, ok := h.clients[ch]; ok {
+			delete(h.clients, ch)
+			close(ch)
+		}
+		h.mu.Unlock()
+	}()
+
+	// Messages from the client are not used, but reading detects when it goes away.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = io.Copy(io.Discard, brw)
+	}()
+
+	b, _ := json.Marshal(snapshot)
+	if err = writeTextFrame(conn, brw.Writer, b); err != nil {
+		return
+	}
+	for {
+		select {
+		case <-done:
+			return
+		case <-req.Context().Done():
+			return
+		case b, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err = writeTextFrame(conn, brw.Writer, b); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeTextFrame writes an unmasked WebSocket text frame.
+func writeTextFrame(conn net.Conn, w *bufio.Writer, payload []byte) error {
+	_ = conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+
+	hdr := []byte{0x81}
+	switch n := len(payload); {
+	case n < 126:
+		hdr = append(hdr, byte(n))
+	case n <= 0xffff:
+		hdr = append(hdr, 126)
+		hdr = binary.BigEndian.AppendUint16(hdr, uint16(n))
+	default:
+		hdr = append(hdr, 127)
+		hdr = binary.BigEndian.AppendUint64(hdr, uint64(n))
+	}
+
+	if _, err := w.Write(hdr); err != nil {
+		return err
+	}
+	if _, err := w.Write(payload); err != nil {
+		return err
+	}
+	return w.Flush()
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+)
+
+// redactedValue replaces credentials in the redacted configuration.
+const redactedValue = "REDACTED"
+
+// redacted returns a copy of the configuration with its credentials
+// replaced, so it can be shown for debugging.
+func (c Config) redacted() Config {
+	c.DetectTimezone.GeoIPURL = redactURL(c.DetectTimezone.GeoIPURL)
+
+	c.Calendars = slices.Clone(c.Calendars)
+	for i := range c.Calendars {
+		cal := &c.Calendars[i]
+		cal.URL = redactURL(cal.URL)
+		cal.Auth = cal.Auth.redacted()
+	}
+
+	c.MQTT.URL = redactURL(c.MQTT.URL)
+	redact(&c.MQTT.Password)
+	c.Travel.URL = redactURL(c.Travel.URL)
+	c.Travel.GeocodeURL = redactURL(c.Travel.GeocodeURL)
+	redact(&c.Travel.APIKey)
+	c.FreeBusy.URL = redactURL(c.FreeBusy.URL)
+	redact(&c.FreeBusy.APIKey)
+	redact(&c.FreeBusy.AccessToken)
+
+	// Webhook URLs carry their secret in the path.
+	c.Notify = slices.Clone(c.Notify)
+	for i := range c.Notify {
+		redact(&c.Notify[i].URL)
+	}
+	return c
+}
+
+func (a Auth) redacted() Auth {
+	redact(&a.Password)
+	redact(&a.Token)
+	redact(&a.Key)
+	redact(&a.ClientSecret)
+	a.TokenURL = redactURL(a.TokenURL)
+	return a
+}
+
+// redact replaces the secret if set.
+func redact(s *string) {
+	if *s != "" {
+		*s = redactedValue
+	}
+}
+
+// redactURL replaces the user info and query values of the URL, which
+// may hold credentials.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		if rawURL == "" {
+			return ""
+		}
+		return redactedValue
+	}
+	if u.User != nil {
+		u.User = url.User(redactedValue)
+	}
+	if u.RawQuery != "" {
+		q := u.Query()
+		for k := range q {
+			q[k] = []string{redactedValue}
+		}
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
+
+// urlRE matches the URLs in error messages.
+var urlRE = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s"]+`)
+
+// redactError returns the message of the error with the URLs in it
+// redacted, as errors of requests include the URLs requested.
+func redactError(err error) string {
+	return urlRE.ReplaceAllStringFunc(err.Error(), redactURL)
+}
@@ -0,0 +1,112 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/render"
+)
+
+// alarmMessage is published on the "calendar.alarm" topic when an event
+// alarm triggers.
+type alarmMessage struct {
+	Module      string    `json:"module"`
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	Start       time.Time `json:"start"`
+	AllDay      bool      `json:"allDay"`
+}
+
+// chimeMessage is published on the chime topic before an event starts.
+type chimeMessage struct {
+	Module   string    `json:"module"`
+	ID       string    `json:"id"`
+	Title    string    `json:"title"`
+	Start    time.Time `json:"start"`
+	LeadTime string    `json:"leadTime"`
+	Sound    string    `json:"sound,omitempty"`
+}
+
+// checkReminders triggers the due event alarms and chimes and expires
+// the reminders, then waits for the next alarm, chime or expiry.
+func (m *Module) checkReminders() {
+	now := m.clock.Now()
+
+	var (
+		triggered []render.Reminder
+		expired   bool
+		chimed    []render.Event
+		next      time.Time
+	)
+	earliest := func(t time.Time) {
+		if !t.IsZero() && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+
+	m.mu.Lock()
+	if m.alarms != nil {
+		triggered = m.alarms.trigger(m.events, now)
+		expired = m.alarms.expire(now)
+		earliest(m.alarms.next(m.events, now))
+	}
+	if m.chimes != nil {
+		chimed = m.chimes.due(m.events, now)
+		earliest(m.chimes.next(m.events, now))
+	}
+	if m.remindTimer != nil {
+		m.remindTimer.Stop()
+		m.remindTimer = nil
+	}
+	if !next.IsZero() {
+		m.remindTimer = time.AfterFunc(next.Sub(now), m.checkReminders)
+	}
+	m.mu.Unlock()
+
+	if m.cfg.Alarms.Publish {
+		for _, r := range triggered {
+			err := m.publish("calendar.alarm", alarmMessage{
+				Module:      m.mod.Name(),
+				ID:          r.ID,
+				Title:       r.Title,
+				Description: r.Description,
+				Start:       r.Time,
+				AllDay:      r.IsAllDay,
+			})
+			if err != nil {
+				m.log.Error("Could not publish alarm", "error", err.Error())
+			}
+		}
+	}
+
+	for _, evnt := range chimed {
+		err := m.publish(m.cfg.Chime.Topic, chimeMessage{
+			Module:   m.mod.Name(),
+			ID:       evnt.ID,
+			Title:    evnt.Title,
+			Start:    evnt.Time,
+			LeadTime: evnt.Time.Sub(evnt.Chime).String(),
+			Sound:    m.cfg.Chime.Sound,
+		})
+		if err != nil {
+			m.log.Error("Could not publish chime", "error", err.Error())
+		}
+	}
+
+	if m.cfg.Alarms.Show && (len(triggered) > 0 || expired) {
+		m.render()
+	}
+}
+
+// acknowledgeAlarm hides the reminder with the given key.
+func (m *Module) acknowledgeAlarm(key string) {
+	m.mu.Lock()
+	ok := m.alarms.acknowledge(key)
+	m.mu.Unlock()
+
+	if ok {
+		m.render()
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/render"
+)
+
+// Views.
+const (
+	viewAgenda   = "agenda"
+	viewTimeline = "timeline"
+	viewRibbon   = "ribbon"
+	viewRoom     = "room"
+)
+
+// Display profiles.
+const (
+	profileDefault = "default"
+	profileEInk    = "eink"
+)
+
+// rtlLanguages are the languages written right to left.
+var rtlLanguages = map[string]bool{
+	"ar": true, "ckb": true, "dv": true, "fa": true, "he": true,
+	"ps": true, "sd": true, "ug": true, "ur": true, "yi": true,
+}
+
+// textDirection returns the text direction of the locale, either "rtl" or "ltr".
+func textDirection(locale string) string {
+	lang, _, _ := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+	if rtlLanguages[strings.ToLower(lang)] {
+		return "rtl"
+	}
+	return "ltr"
+}
+
+// validateView checks that the view is known.
+func validateView(view string) error {
+	switch view {
+	case viewAgenda, viewTimeline, viewRibbon, viewRoom:
+		return nil
+	default:
+		return fmt.Errorf("unknown view %q", view)
+	}
+}
+
+// Page indicators.
+const (
+	pageDots  = "dots"
+	pageCount = "count"
+	pageNone  = "none"
+)
+
+// validatePageIndicator checks that the page indicator is known.
+func validatePageIndicator(indicator string) error {
+	switch indicator {
+	case pageDots, pageCount, pageNone:
+		return nil
+	default:
+		return fmt.Errorf("unknown page indicator %q", indicator)
+	}
+}
+
+// validateProfile checks that the display profile is known.
+func validateProfile(profile string) error {
+	switch profile {
+	case "", profileDefault, profileEInk:
+		return nil
+	default:
+		return fmt.Errorf("unknown display profile %q", profile)
+	}
+}
+
+// formatRemaining formats the time remaining until an event ends in
+// whole minutes, rounded up, e.g. "40 min" or "2 h 5 min".
+func formatRemaining(d time.Duration) string {
+	mins := int((d + time.Minute - 1) / time.Minute)
+	switch {
+	case mins < 60:
+		return fmt.Sprintf("%d min", mins)
+	case mins%60 == 0:
+		return fmt.Sprintf("%d h", mins/60)
+	default:
+		return fmt.Sprintf("%d h %d min", mins/60, mins%60)
+	}
+}
+
+// viewModel returns the view model of the events in the given view at now,
+// computing their day relative fields in place using their day index.
+// Fields depending on the state of the module, such as errors and pages,
+// are left for the caller.
+func (p *pipeline) viewModel(view string, events []render.Event, days dayIndex, now time.Time) render.Model {
+	for _, i := range days.weekStarts(p.weekStart) {
+		events[i].IsNewWeek = true
+	}
+
+	// Day relative fields are computed on each render so they
+	// stay correct across midnight.
+	from, to := days.day(now, p.tz)
+	today := events[from:to]
+	for i := range today {
+		today[i].IsToday = true
+	}
+	for i := range events {
+		events[i].Urgency = urgency(events[i].Time, events[i].IsAllDay, now)
+		if !events[i].IsAllDay && !events[i].Time.After(now) && events[i].End.After(now) {
+			events[i].EndsIn = formatRemaining(events[i].End.Sub(now))
+		}
+		if p.cfg.PulseBefore > 0 && !events[i].IsAllDay && events[i].Time.After(now) {
+			events[i].IsPulsing = events[i].Time.Sub(now) <= p.cfg.PulseBefore
+		}
+		events[i].AltDate = p.altDate(events[i].Time)
+	}
+
+	model := render.Model{
+		Now:           now,
+		View:          view,
+		Profile:       p.cfg.DisplayProfile,
+		Lang:          p.cfg.Locale,
+		Dir:           textDirection(p.cfg.Locale),
+		Events:        events,
+		Quiet:         p.quietMode(now),
+		PageIndicator: p.cfg.PageIndicator,
+	}
+	if model.Quiet == quietMinimal {
+		for i := range events {
+			if !events[i].IsAllDay && events[i].Time.After(now) {
+				model.Next = &events[i]
+				break
+			}
+		}
+	}
+	switch {
+	case model.Quiet != "":
+		// Only the next event, if anything, is rendered in quiet hours.
+	case view == viewTimeline:
+		model.Timeline = newTimeline(today, now.In(p.tz), p.hours)
+	case view == viewRibbon:
+		model.Ribbon = newRibbon(events, now, p.cfg.RibbonHours)
+	case view == viewRoom:
+		model.Room = newRoom(events, now)
+	}
+	return model
+}
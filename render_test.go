@@ -0,0 +1,155 @@
+//go:build !js
+
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+	"github.com/glasslabs/calendar/pkg/render"
+)
+
+var update = flag.Bool("update", false, "Update the golden files.")
+
+func TestRender(t *testing.T) {
+	app, evnts := loadFixture(t)
+	now := app.clock.Now()
+
+	for _, view := range []string{viewAgenda, viewTimeline, viewRibbon, viewRoom} {
+		t.Run(view, func(t *testing.T) {
+			events := app.pipe.withAnniversaries(app.pipe.toEvents(evnts), now)
+
+			got, err := render.Render(app.pipe.viewModel(view, events, newDayIndex(events), now))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", "render-"+view+".golden")
+			if *update {
+				if err = os.WriteFile(golden, []byte(got), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("rendered %s view does not match %s, run with -update to update it:\n%s", view, golden, got)
+			}
+		})
+	}
+}
+
+func TestViewModelAllDayWestOfUTC(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Timezone = "America/Los_Angeles"
+	p, err := newPipeline(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evnts := []ical.Event{{
+		UID:     "holiday",
+		Summary: "Holiday",
+		Start:   time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC),
+		End:     time.Date(2024, 6, 4, 0, 0, 0, 0, time.UTC),
+		AllDay:  true,
+	}}
+	events := p.toEvents(evnts)
+	now := time.Date(2024, 6, 3, 9, 0, 0, 0, p.tz)
+
+	model := p.viewModel(viewAgenda, events, newDayIndex(events), now)
+
+	if got := model.Events[0].Time.Format(time.DateOnly); got != "2024-06-03" {
+		t.Errorf("got all-day event on %s, want 2024-06-03", got)
+	}
+	if !model.Events[0].IsToday {
+		t.Error("got all-day event not today")
+	}
+}
+
+func TestDayIndexAllDayWestOfUTC(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Timezone = "America/Los_Angeles"
+	p, err := newPipeline(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evnts := []ical.Event{
+		{
+			UID:     "holiday",
+			Summary: "Holiday",
+			Start:   time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC),
+			End:     time.Date(2024, 6, 4, 0, 0, 0, 0, time.UTC),
+			AllDay:  true,
+		},
+		{
+			UID:     "dinner",
+			Summary: "Dinner",
+			Start:   time.Date(2024, 6, 3, 3, 0, 0, 0, time.UTC),
+			End:     time.Date(2024, 6, 3, 4, 0, 0, 0, time.UTC),
+		},
+	}
+	events := p.toEvents(evnts)
+	days := newDayIndex(events)
+
+	tests := []struct {
+		day  time.Time
+		want []string
+	}{
+		{day: time.Date(2024, 6, 2, 12, 0, 0, 0, p.tz), want: []string{"Dinner"}},
+		{day: time.Date(2024, 6, 3, 12, 0, 0, 0, p.tz), want: []string{"Holiday"}},
+	}
+	for _, test := range tests {
+		from, to := days.day(test.day, p.tz)
+		var got []string
+		for _, evnt := range events[from:to] {
+			got = append(got, evnt.Title)
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("got %v on %s, want %v", got, test.day.Format(time.DateOnly), test.want)
+		}
+	}
+}
+
+func BenchmarkRender(b *testing.B) {
+	app, evnts := loadFixture(b)
+	now := app.clock.Now()
+	events := app.pipe.toEvents(evnts)
+	model := app.pipe.viewModel(viewAgenda, events, newDayIndex(events), now)
+	r, err := render.New()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		if _, err = r.Render(model); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// loadFixture loads the calendars of the fixture configuration.
+func loadFixture(tb testing.TB) (*standalone, []ical.Event) {
+	tb.Helper()
+
+	cfg, err := readConfig("testdata/config.yaml")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	app, err := newStandalone(cfg)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return app, app.load(context.Background())
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/render"
+)
+
+// newRibbon returns the ribbon of timed events in the given number of
+// hours from the start of the current hour.
+func newRibbon(events []render.Event, now time.Time, hours int) *render.Ribbon {
+	from := now.Truncate(time.Hour)
+	to := from.Add(time.Duration(hours) * time.Hour)
+	total := to.Sub(from)
+	pos := func(t time.Time) float64 {
+		switch {
+		case t.Before(from):
+			t = from
+		case t.After(to):
+			t = to
+		}
+		return 100 * float64(t.Sub(from)) / float64(total)
+	}
+
+	r := &render.Ribbon{Now: pos(now)}
+	for t := from; t.Before(to); t = t.Add(time.Hour) {
+		r.Hours = append(r.Hours, render.RibbonHour{Time: t, Left: pos(t)})
+	}
+
+	var laneEnds []time.Time
+	for _, evnt := range events {
+		end := evnt.End
+		if !end.After(evnt.Time) {
+			end = evnt.Time.Add(15 * time.Minute)
+		}
+		if evnt.IsAllDay || !end.After(from) || !evnt.Time.Before(to) {
+			continue
+		}
+
+		lane := -1
+		for i, laneEnd := range laneEnds {
+			if !evnt.Time.Before(laneEnd) {
+				lane = i
+				break
+			}
+		}
+		if lane < 0 {
+			lane = len(laneEnds)
+			laneEnds = append(laneEnds, time.Time{})
+		}
+		laneEnds[lane] = end
+
+		r.Blocks = append(r.Blocks, render.RibbonBlock{
+			Event: evnt,
+			Left:  pos(evnt.Time),
+			Width: pos(end) - pos(evnt.Time),
+			Lane:  lane,
+		})
+	}
+	r.Lanes = max(len(laneEnds), 1)
+	return r
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/render"
+)
+
+// newRoom returns the occupancy of a room from its timed events.
+func newRoom(events []render.Event, now time.Time) *render.Room {
+	r := &render.Room{}
+	for i, evnt := range events {
+		if evnt.IsAllDay || evnt.Time.After(now) || !evnt.End.After(now) {
+			continue
+		}
+		if r.Current == nil {
+			r.Current = &events[i]
+		}
+		r.Busy = true
+		if evnt.End.After(r.Until) {
+			r.Until = evnt.End
+		}
+	}
+
+	if r.Busy {
+		// Meetings starting as one ends keep the room busy.
+		for extended := true; extended; {
+			extended = false
+			for _, evnt := range events {
+				if evnt.IsAllDay || evnt.Time.After(r.Until) || !evnt.End.After(r.Until) {
+					continue
+				}
+				r.Until = evnt.End
+				extended = true
+			}
+		}
+		return r
+	}
+
+	for i, evnt := range events {
+		if evnt.IsAllDay || !evnt.Time.After(now) {
+			continue
+		}
+		if isToday(evnt.Time, now) {
+			r.Next = &events[i]
+			r.FreeMinutes = int(evnt.Time.Sub(now) / time.Minute)
+		}
+		break
+	}
+	return r
+}
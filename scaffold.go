@@ -0,0 +1,157 @@
+//go:build !js
+
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed README.md
+var readme string
+
+// readmeOptionRE matches the option headings of the readme, e.g.
+// "### Max Days (maxDays)".
+var readmeOptionRE = regexp.MustCompile(`^### .+ \((.+)\)$`)
+
+// printDefaultConfig writes the default configuration to w, with each
+// option commented with its description from the readme and the calendars
+// commented with an example of each calendar type.
+func printDefaultConfig(w io.Writer) error {
+	docs, examples := readmeDocs(readme)
+
+	node, err := configNode(reflect.ValueOf(NewConfig()))
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		key := node.Content[i]
+		key.HeadComment = docs[key.Value]
+		if key.Value == "calendars" {
+			node.Content[i+1].FootComment = examples
+		}
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err = enc.Encode(node); err != nil {
+		return fmt.Errorf("encoding config: %w", err)
+	}
+	return enc.Close()
+}
+
+// configNode returns the YAML node of the configuration value, keeping
+// zero values so that every option is listed, and formatting durations
+// as they are written in configurations.
+func configNode(v reflect.Value) (*yaml.Node, error) {
+	if d, ok := v.Interface().(time.Duration); ok {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: formatDuration(d)}, nil
+	}
+	if v.Kind() != reflect.Struct {
+		var n yaml.Node
+		if err := n.Encode(v.Interface()); err != nil {
+			return nil, fmt.Errorf("encoding config: %w", err)
+		}
+		return &n, nil
+	}
+
+	n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for i := range v.NumField() {
+		name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		val, err := configNode(v.Field(i))
+		if err != nil {
+			return nil, err
+		}
+		n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}, val)
+	}
+	return n, nil
+}
+
+// formatDuration formats the duration without trailing zero units,
+// e.g. "30m" rather than "30m0s".
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// readmeDocs returns the first paragraph describing each top level
+// option in the readme by key, and the calendar examples of each
+// calendar type as comments.
+func readmeDocs(text string) (map[string]string, string) {
+	docs := map[string]string{}
+	examples := []string{
+		"Calendars to show, e.g.",
+		"",
+		"- name: Holidays",
+		"  url: https://www.calendarlabs.com/ical-calendar/ics/68/South_Africa_Holidays.ics",
+	}
+
+	var (
+		key       string
+		typeDocs  bool
+		para      []string
+		inExample bool
+	)
+	for _, line := range strings.Split(text, "\n") {
+		if m := readmeOptionRE.FindStringSubmatch(line); m != nil {
+			first, _, _ := strings.Cut(m[1], ",")
+			key, _, _ = strings.Cut(first, ".")
+			typeDocs = first == "calendar.[].type" || first == "calendar.[].preset"
+			para = nil
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			key, typeDocs = "", false
+			continue
+		}
+
+		// The examples of the calendar types are written as calendars,
+		// of which only the calendar entries are kept.
+		if typeDocs && strings.HasPrefix(line, "```") {
+			inExample = line == "```yaml"
+			if inExample {
+				examples = append(examples, "")
+			}
+			continue
+		}
+		if inExample {
+			if line != "calendars:" {
+				examples = append(examples, strings.TrimPrefix(line, "  "))
+			}
+			continue
+		}
+
+		if key == "" || key == "calendar" || docs[key] != "" {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "*") && strings.HasSuffix(line, "*"):
+		case line == "" && len(para) > 0:
+			// Examples following the paragraph are not included.
+			doc := strings.Join(para, "\n")
+			if strings.HasSuffix(doc, ", e.g.") {
+				doc = strings.TrimSuffix(doc, ", e.g.") + "."
+			}
+			docs[key] = doc
+		case line != "":
+			para = append(para, line)
+		}
+	}
+	return docs, strings.Join(examples, "\n")
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// secondaryCalendar formats a date in an alternative calendar system.
+type secondaryCalendar func(y int, m time.Month, d int) string
+
+func newSecondaryCalendar(name string) (secondaryCalendar, error) {
+	switch name {
+	case "":
+		return nil, nil
+	case "hebrew":
+		return hebrewDate, nil
+	case "hijri":
+		return hijriDate, nil
+	case "chinese":
+		return chineseDate, nil
+	default:
+		return nil, fmt.Errorf("unknown secondary calendar %q", name)
+	}
+}
+
+// epochDay returns the number of days from 1970-01-01 to the date.
+func epochDay(y int, m time.Month, d int) int {
+	return int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
+
+// fixedEpoch is the fixed day number, counting from 0001-01-01 as day 1,
+// of 1970-01-01.
+const fixedEpoch = 719163
+
+// Hijri dates use the tabular Islamic calendar, which may differ by a
+// day or two from calendars based on the sighting of the moon.
+
+const hijriEpoch = 227015
+
+var hijriMonths = [12]string{
+	"Muharram", "Safar", "Rabi I", "Rabi II", "Jumada I", "Jumada II",
+	"Rajab", "Sha'ban", "Ramadan", "Shawwal", "Dhu al-Qi'dah", "Dhu al-Hijjah",
+}
+
+func hijriDate(y int, m time.Month, d int) string {
+	date := epochDay(y, m, d) + fixedEpoch
+
+	year := floorDiv(30*(date-hijriEpoch)+10646, 10631)
+	month := min(floorDiv(11*(date-fixedFromHijri(year, 1, 1))+330, 325), 12)
+	day := date - fixedFromHijri(year, month, 1) + 1
+	return strconv.Itoa(day) + " " + hijriMonths[month-1] + " " + strconv.Itoa(year)
+}
+
+func fixedFromHijri(y, m, d int) int {
+	return d + 29*(m-1) + floorDiv(6*m-1, 11) + (y-1)*354 + floorDiv(3+11*y, 30) + hijriEpoch - 1
+}
+
+// Hebrew dates use the arithmetic Hebrew calendar, with months numbered
+// from Nisan.
+
+const hebrewEpoch = -1373427
+
+const (
+	hebrewNisan      = 1
+	hebrewIyyar      = 2
+	hebrewTammuz     = 4
+	hebrewElul       = 6
+	hebrewTishri     = 7
+	hebrewMarheshvan = 8
+	hebrewKislev     = 9
+	hebrewTevet      = 10
+	hebrewAdar       = 12
+	hebrewAdarII     = 13
+)
+
+var hebrewMonths = [13]string{
+	"Nisan", "Iyar", "Sivan", "Tammuz", "Av", "Elul", "Tishrei",
+	"Heshvan", "Kislev", "Tevet", "Shevat", "Adar", "Adar II",
+}
+
+func hebrewDate(y int, m time.Month, d int) string {
+	date := epochDay(y, m, d) + fixedEpoch
+
+	year := int(math.Floor(float64(date-hebrewEpoch) / (35975351.0 / 98496)))
+	for hebrewNewYear(year+1) <= date {
+		year++
+	}
+
+	month := hebrewNisan
+	if date < fixedFromHebrew(year, hebrewNisan, 1) {
+		month = hebrewTishri
+	}
+	for date > fixedFromHebrew(year, month, hebrewMonthDays(year, month)) {
+		month++
+	}
+	day := date - fixedFromHebrew(year, month, 1) + 1
+
+	name := hebrewMonths[month-1]
+	if month == hebrewAdar && hebrewLeapYear(year) {
+		name = "Adar I"
+	}
+	return strconv.Itoa(day) + " " + name + " " + strconv.Itoa(year)
+}
+
+func hebrewLeapYear(y int) bool {
+	return floorMod(7*y+1, 19) < 7
+}
+
+func hebrewLastMonth(y int) int {
+	if hebrewLeapYear(y) {
+		return hebrewAdarII
+	}
+	return hebrewAdar
+}
+
+func hebrewElapsedDays(y int) int {
+	months := floorDiv(235*y-234, 19)
+	parts := 12084 + 13753*months
+	days := 29*months + floorDiv(parts, 25920)
+	if floorMod(3*(days+1), 7) < 3 {
+		days++
+	}
+	return days
+}
+
+func hebrewNewYear(y int) int {
+	ny0, ny1, ny2 := hebrewElapsedDays(y-1), hebrewElapsedDays(y), hebrewElapsedDays(y+1)
+
+	correction := 0
+	switch {
+	case ny2-ny1 == 356:
+		correction = 2
+	case ny1-ny0 == 382:
+		correction = 1
+	}
+	return hebrewEpoch + ny1 + correction
+}
+
+func hebrewMonthDays(y, m int) int {
+	yearDays := hebrewNewYear(y+1) - hebrewNewYear(y)
+
+	switch {
+	case m == hebrewIyyar, m == hebrewTammuz, m == hebrewElul, m == hebrewTevet, m == hebrewAdarII:
+		return 29
+	case m == hebrewAdar && !hebrewLeapYear(y):
+		return 29
+	case m == hebrewMarheshvan && yearDays != 355 && yearDays != 385:
+		return 29
+	case m == hebrewKislev && (yearDays == 353 || yearDays == 383):
+		return 29
+	default:
+		return 30
+	}
+}
+
+func fixedFromHebrew(y, m, d int) int {
+	date := hebrewNewYear(y) + d - 1
+	if m < hebrewTishri {
+		for i := hebrewTishri; i <= hebrewLastMonth(y); i++ {
+			date += hebrewMonthDays(y, i)
+		}
+		for i := hebrewNisan; i < m; i++ {
+			date += hebrewMonthDays(y, i)
+		}
+		return date
+	}
+	for i := hebrewTishri; i < m; i++ {
+		date += hebrewMonthDays(y, i)
+	}
+	return date
+}
+
+// Chinese dates are computed astronomically in China standard time,
+// with months starting at new moons and the winter solstice in the
+// eleventh month.
+
+const meanSynodicMonth = 29.530588861
+
+var (
+	chineseMonths = [12]string{"正", "二", "三", "四", "五", "六", "七", "八", "九", "十", "冬", "腊"}
+	chineseDigits = [11]string{"十", "一", "二", "三", "四", "五", "六", "七", "八", "九", "十"}
+)
+
+func chineseDate(y int, m time.Month, d int) string {
+	date := epochDay(y, m, d)
+
+	s1 := chineseWinterSolstice(date)
+	s2 := chineseWinterSolstice(s1 + 370)
+	m12 := chineseNewMoonOnOrAfter(s1 + 1)
+	nextM11 := chineseNewMoonBefore(s2 + 1)
+	leapYear := math.Round(float64(nextM11-m12)/meanSynodicMonth) == 12
+
+	start := chineseNewMoonBefore(date + 1)
+	month := int(math.Round(float64(start-m12) / meanSynodicMonth))
+	if leapYear && chinesePriorLeapMonth(m12, start) {
+		month--
+	}
+	month = floorMod(month-1, 12) + 1
+	leapMonth := leapYear && chineseNoMajorTerm(start) && !chinesePriorLeapMonth(m12, chineseNewMoonBefore(start))
+	day := date - start + 1
+
+	s := chineseMonths[month-1] + "月"
+	if leapMonth {
+		s = "闰" + s
+	}
+	switch {
+	case day <= 10:
+		s += "初" + chineseDigits[day]
+	case day < 20:
+		s += "十" + chineseDigits[day-10]
+	case day == 20:
+		s += "二十"
+	case day < 30:
+		s += "廿" + chineseDigits[day-20]
+	default:
+		s += "三十"
+	}
+	return s
+}
+
+// chineseMidnight returns the julian day of midnight in China on the day.
+func chineseMidnight(day int) float64 {
+	return float64(day) + julianUnixEpoch - 8.0/24
+}
+
+// chineseDay returns the day in China of the julian day.
+func chineseDay(jd float64) int {
+	return int(math.Floor(jd - julianUnixEpoch + 8.0/24))
+}
+
+// chineseWinterSolstice returns the day in China of the last winter
+// solstice on or before the day.
+func chineseWinterSolstice(day int) int {
+	y := time.Unix(int64(day)*86400, 0).UTC().Year()
+	s := chineseDay(winterSolstice(y))
+	if s > day {
+		s = chineseDay(winterSolstice(y - 1))
+	}
+	return s
+}
+
+// winterSolstice returns the julian day of the winter solstice in the year.
+func winterSolstice(y int) float64 {
+	lo := toJulian(time.Date(y, time.December, 15, 0, 0, 0, 0, time.UTC))
+	hi := lo + 12
+	for hi-lo > 1e-5 {
+		mid := (lo + hi) / 2
+		if normDeg(solarLongitude(mid)-270+180) < 180 {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
+
+// chineseNewMoonBefore returns the day in China of the last new moon
+// before midnight in China on the day.
+func chineseNewMoonBefore(day int) int {
+	x := chineseMidnight(day)
+	k := math.Floor((x-2451550.09766)/meanSynodicMonth) + 1
+	for moonPhaseJulian(k) >= x {
+		k--
+	}
+	return chineseDay(moonPhaseJulian(k))
+}
+
+// chineseNewMoonOnOrAfter returns the day in China of the first new moon
+// at or after midnight in China on the day.
+func chineseNewMoonOnOrAfter(day int) int {
+	x := chineseMidnight(day)
+	k := math.Floor((x-2451550.09766)/meanSynodicMonth) - 1
+	for moonPhaseJulian(k) < x {
+		k++
+	}
+	return chineseDay(moonPhaseJulian(k))
+}
+
+func chineseMajorTerm(day int) int {
+	s := solarLongitude(chineseMidnight(day))
+	return floorMod(2+int(math.Floor(s/30))-1, 12) + 1
+}
+
+func chineseNoMajorTerm(day int) bool {
+	return chineseMajorTerm(day) == chineseMajorTerm(chineseNewMoonOnOrAfter(day+1))
+}
+
+func chinesePriorLeapMonth(from, day int) bool {
+	for ; day >= from; day = chineseNewMoonBefore(day) {
+		if chineseNoMajorTerm(day) {
+			return true
+		}
+	}
+	return false
+}
+
+// solarLongitude returns the apparent longitude of the sun in degrees.
+func solarLongitude(jd float64) float64 {
+	t := (jd - julianJ2000) / 36525
+	l0 := 280.46646 + 36000.76983*t
+	m := 357.52911 + 35999.05029*t
+	c := (1.914602-0.004817*t)*sinDeg(m) + (0.019993-0.000101*t)*sinDeg(2*m) + 0.000289*sinDeg(3*m)
+	return normDeg(l0 + c - 0.00569 - 0.00478*sinDeg(125.04-1934.136*t))
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+func floorMod(a, b int) int {
+	return a - b*floorDiv(a, b)
+}
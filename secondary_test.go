@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSecondaryCalendars(t *testing.T) {
+	tests := []struct {
+		calendar string
+		date     string
+		want     string
+	}{
+		// Hebrew new years, and the months of the leap year 5784.
+		{calendar: "hebrew", date: "2023-09-15", want: "29 Elul 5783"},
+		{calendar: "hebrew", date: "2023-09-16", want: "1 Tishrei 5784"},
+		{calendar: "hebrew", date: "2024-10-03", want: "1 Tishrei 5785"},
+		{calendar: "hebrew", date: "2025-09-23", want: "1 Tishrei 5786"},
+		{calendar: "hebrew", date: "2024-02-10", want: "1 Adar I 5784"},
+		{calendar: "hebrew", date: "2024-03-10", want: "30 Adar I 5784"},
+		{calendar: "hebrew", date: "2024-03-11", want: "1 Adar II 5784"},
+		{calendar: "hebrew", date: "2024-04-09", want: "1 Nisan 5784"},
+		{calendar: "hebrew", date: "2025-03-01", want: "1 Adar 5785"},
+
+		// Tabular Hijri new years and the start of Ramadan.
+		{calendar: "hijri", date: "2023-07-19", want: "1 Muharram 1445"},
+		{calendar: "hijri", date: "2024-03-11", want: "1 Ramadan 1445"},
+		{calendar: "hijri", date: "2024-07-07", want: "30 Dhu al-Hijjah 1445"},
+		{calendar: "hijri", date: "2024-07-08", want: "1 Muharram 1446"},
+		{calendar: "hijri", date: "2025-03-01", want: "1 Ramadan 1446"},
+		{calendar: "hijri", date: "2025-06-27", want: "1 Muharram 1447"},
+
+		// Chinese new years and the boundaries of leap months.
+		{calendar: "chinese", date: "2023-01-21", want: "腊月三十"},
+		{calendar: "chinese", date: "2023-01-22", want: "正月初一"},
+		{calendar: "chinese", date: "2024-02-10", want: "正月初一"},
+		{calendar: "chinese", date: "2025-01-29", want: "正月初一"},
+		{calendar: "chinese", date: "2024-09-17", want: "八月十五"},
+		{calendar: "chinese", date: "2020-05-23", want: "闰四月初一"},
+		{calendar: "chinese", date: "2023-03-22", want: "闰二月初一"},
+		{calendar: "chinese", date: "2023-04-20", want: "三月初一"},
+		{calendar: "chinese", date: "2025-07-25", want: "闰六月初一"},
+		{calendar: "chinese", date: "2025-08-22", want: "闰六月廿九"},
+		{calendar: "chinese", date: "2025-08-23", want: "七月初一"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.calendar+" "+test.date, func(t *testing.T) {
+			cal, err := newSecondaryCalendar(test.calendar)
+			if err != nil {
+				t.Fatal(err)
+			}
+			date, err := time.Parse(time.DateOnly, test.date)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := cal(date.Date()); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
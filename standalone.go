@@ -0,0 +1,423 @@
+//go:build !js
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+	"github.com/glasslabs/calendar/pkg/render"
+	"gopkg.in/yaml.v3"
+)
+
+// The standalone build runs outside of looking glass to help
+// diagnose configurations and share the merged calendar.
+func main() {
+	var (
+		cfgPath = flag.String("config", "", "The path to the module configuration `file`.")
+		dump    = flag.Bool("dump", false, "Fetch all calendars once and print the merged events.")
+		format  = flag.String("format", "table", "The dump output `format`, either table, json or html.")
+		serve   = flag.String("serve", "", "Serve the merged events as an ICS feed on the given `address`.")
+		defCfg  = flag.Bool("print-config", false, "Print the default configuration with every option documented.")
+	)
+	flag.Parse()
+
+	if *defCfg {
+		if err := printDefaultConfig(os.Stdout); err != nil {
+			exitErr(err)
+		}
+		return
+	}
+
+	if !*dump && *serve == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	cfg, err := readConfig(*cfgPath)
+	if err != nil {
+		exitErr(err)
+	}
+	app, err := newStandalone(cfg)
+	if err != nil {
+		exitErr(err)
+	}
+	app.path = *cfgPath
+
+	switch {
+	case *dump:
+		err = app.dump(ctx, *format, os.Stdout)
+	default:
+		err = app.serve(ctx, *serve)
+	}
+	if err != nil {
+		exitErr(err)
+	}
+}
+
+func exitErr(err error) {
+	_, _ = fmt.Fprintln(os.Stderr, "Error:", err)
+	os.Exit(1)
+}
+
+// readConfig reads the module configuration from the file at path.
+func readConfig(path string) (Config, error) {
+	cfg := NewConfig()
+	if path == "" {
+		return cfg, errors.New("a config file is required")
+	}
+
+	b, err := os.ReadFile(path) //nolint:gosec // The path is provided by the user.
+	if err != nil {
+		return cfg, fmt.Errorf("reading config: %w", err)
+	}
+	if err = yaml.Unmarshal(b, &cfg); err != nil {
+		return cfg, fmt.Errorf("parsing config: %w", err)
+	}
+	cfg.removeDisabled()
+	if err = cfg.expandEnv(); err != nil {
+		return cfg, fmt.Errorf("parsing config: %w", err)
+	}
+	if err = cfg.readSecretFiles(); err != nil {
+		return cfg, fmt.Errorf("parsing config: %w", err)
+	}
+	if err = cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("invalid config:\n%w", err)
+	}
+	if err = cfg.detectTimezone(context.Background()); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Could not detect timezone: %v\n", err)
+	}
+	return cfg, nil
+}
+
+// standalone loads the configured calendars outside of looking glass.
+type standalone struct {
+	path    string
+	cfg     Config
+	clock   Clock
+	pipe    *pipeline
+	fetcher *ical.Fetcher
+	sources []*source
+
+	// due holds when each source is next loaded by serve.
+	due map[*source]time.Time
+
+	// mu guards the fetch status of the sources and the loaded events,
+	// which are served by the debug listener.
+	mu      sync.Mutex
+	events  []ical.Event
+	updated time.Time
+}
+
+func newStandalone(cfg Config) (*standalone, error) {
+	clock, err := newClock(cfg.Fixture)
+	if err != nil {
+		return nil, err
+	}
+	pipe, err := newPipeline(cfg)
+	if err != nil {
+		return nil, err
+	}
+	f := newFetcher(cfg)
+	srcs, err := newSources(cfg, f)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SharedCache {
+		cache := newMemoryCache()
+		for _, src := range srcs {
+			src.cache = cache
+		}
+	}
+
+	return &standalone{
+		cfg:     cfg,
+		clock:   clock,
+		pipe:    pipe,
+		fetcher: f,
+		sources: srcs,
+	}, nil
+}
+
+// load fetches the calendars that are due on their interval, returning
+// the selected events of all calendars.
+func (a *standalone) load(ctx context.Context) []ical.Event {
+	start := a.clock.Now()
+	end := a.pipe.windowEnd(start)
+
+	if a.due == nil {
+		a.due = make(map[*source]time.Time, len(a.sources))
+	}
+
+	var evnts []ical.Event
+	for _, src := range a.sources {
+		if !start.Before(a.due[src]) {
+			a.due[src] = start.Add(src.interval)
+			a.loadSource(ctx, src, start, end)
+		}
+
+		// As in the module, a calendar that fails to load keeps the
+		// events it last loaded.
+		a.mu.Lock()
+		evnts = append(evnts, src.events...)
+		a.mu.Unlock()
+	}
+	evnts = append(evnts, a.pipe.generate(start, end)...)
+	evnts, _ = a.pipe.selectEvents(evnts)
+
+	a.mu.Lock()
+	a.events, a.updated = evnts, start
+	a.mu.Unlock()
+	return evnts
+}
+
+// loadSource fetches the calendar of the source within the window from
+// start to end, recording the result.
+func (a *standalone) loadSource(ctx context.Context, src *source, start, end time.Time) {
+	cal, err := src.load(ctx, a.fetcher, start, end)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Could not load calendar %s: %v\n", src.name(), err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	src.err = err
+	if err != nil {
+		src.failures++
+		return
+	}
+	src.title = cal.Name
+	src.fetched = start
+	src.failures = 0
+	src.events = cal.Events
+}
+
+// debugInfo returns the merged events, the fetch status of the sources
+// and the effective configuration with its credentials redacted.
+func (a *standalone) debugInfo() debugInfo {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	info := debugInfo{
+		Updated: a.updated,
+		Config:  a.cfg.redacted(),
+		Sources: make([]debugSource, 0, len(a.sources)),
+		Events:  a.pipe.toEvents(a.events),
+	}
+	for _, src := range a.sources {
+		info.Sources = append(info.Sources, newDebugSource(src))
+	}
+	return info
+}
+
+// serveDebug serves the debug information as JSON on addr until the
+// context is done.
+func (a *standalone) serveDebug(ctx context.Context, addr string) error {
+	srv := &http.Server{
+		Addr: addr,
+		Handler: http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.Header().Set("Content-Type", "application/json")
+			enc := json.NewEncoder(rw)
+			enc.SetIndent("", "  ")
+			_ = enc.Encode(a.debugInfo())
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serving debug information: %w", err)
+	}
+	return nil
+}
+
+// reloadConfig reads the configuration again, along with the secret
+// files it references, replacing the sources with ones built from it.
+// The current configuration is kept when the new one is invalid.
+func (a *standalone) reloadConfig() error {
+	cfg, err := readConfig(a.path)
+	if err != nil {
+		return err
+	}
+	next, err := newStandalone(cfg)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.cfg, a.clock, a.pipe = next.cfg, next.clock, next.pipe
+	a.fetcher, a.sources, a.due = next.fetcher, next.sources, nil
+	return nil
+}
+
+// clearCache removes the cached calendars, so they are downloaded again
+// on the next load.
+func (a *standalone) clearCache() {
+	for _, src := range a.sources {
+		if src.cache != nil {
+			src.cache.remove(src.cal.URL)
+		}
+	}
+	a.due = nil
+}
+
+// tick returns the interval at which serve checks for due calendars,
+// which is the shortest calendar interval.
+func (a *standalone) tick() time.Duration {
+	d := a.cfg.Interval
+	for _, src := range a.sources {
+		d = min(d, src.interval)
+	}
+	return d
+}
+
+// dump fetches all calendars once and writes the merged events to w.
+func (a *standalone) dump(ctx context.Context, format string, w io.Writer) error {
+	now := a.clock.Now()
+	events := a.pipe.withAnniversaries(a.pipe.toEvents(a.load(ctx)), now)
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(events)
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "TIME\tALL DAY\tTITLE")
+		for _, evnt := range events {
+			_, _ = fmt.Fprintf(tw, "%s\t%t\t%s\n", evnt.Time.Format("Mon Jan _2 15:04"), evnt.IsAllDay, evnt.Title)
+		}
+		return tw.Flush()
+	case "html":
+		view := a.cfg.View
+		if len(a.cfg.Views) > 0 {
+			view = a.cfg.Views[0]
+		}
+		out, err := render.Render(a.pipe.viewModel(view, events, newDayIndex(events), now))
+		if err != nil {
+			return fmt.Errorf("rendering html: %w", err)
+		}
+		_, err = io.WriteString(w, out)
+		return err
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// serve serves the merged events as an ICS feed and pushes changes to
+// them over a WebSocket, reloading each calendar on its interval and all
+// of them immediately on SIGHUP, along with the configuration.
+func (a *standalone) serve(ctx context.Context, addr string) error {
+	var (
+		mu  sync.Mutex
+		ics []byte
+	)
+	hub := newPushHub()
+	reload := func() {
+		evnts := a.load(ctx)
+		hub.Update(evnts)
+
+		var buf bytes.Buffer
+		if err := ical.Encode(&buf, evnts); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, "Could not encode calendar:", err)
+			return
+		}
+
+		mu.Lock()
+		ics = buf.Bytes()
+		mu.Unlock()
+	}
+	reload()
+
+	if a.cfg.DebugAddr != "" {
+		go func() {
+			if err := a.serveDebug(ctx, a.cfg.DebugAddr); err != nil {
+				_, _ = fmt.Fprintln(os.Stderr, "Could not serve debug information:", err)
+			}
+		}()
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	go func() {
+		ticker := time.NewTicker(a.tick())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				reload()
+			case <-hup:
+				// The configuration and secret files are read again,
+				// and as with a refresh message, the calendars are
+				// downloaded again rather than read from the cache.
+				if err := a.reloadConfig(); err != nil {
+					_, _ = fmt.Fprintln(os.Stderr, "Could not reload config:", err)
+					a.clearCache()
+				}
+				ticker.Reset(a.tick())
+				reload()
+			}
+		}
+	}()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/calendar.ics", func(rw http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		b := ics
+		mu.Unlock()
+
+		rw.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		_, _ = rw.Write(b)
+	})
+
+	mux.Handle("/events", hub)
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serving calendar: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+//go:build !js
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStandaloneLoadHonorsIntervals(t *testing.T) {
+	now := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	newFeed := func(requests *int, summary string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			*requests++
+			_, _ = rw.Write([]byte("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:" + summary + "\r\nSUMMARY:" + summary +
+				"\r\nDTSTAMP:20240601T000000Z\r\nDTSTART:20240603T120000Z\r\nDTEND:20240603T130000Z\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"))
+		}))
+	}
+	var fastRequests, slowRequests int
+	fast := newFeed(&fastRequests, "Fast")
+	defer fast.Close()
+	slow := newFeed(&slowRequests, "Slow")
+	defer slow.Close()
+
+	cfg := NewConfig()
+	cfg.Interval = time.Hour
+	cfg.Calendars = []Calendar{
+		{Name: "fast", URL: fast.URL, Interval: 10 * time.Minute},
+		{Name: "slow", URL: slow.URL},
+	}
+	app, err := newStandalone(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := app.tick(); got != 10*time.Minute {
+		t.Errorf("got tick %s, want 10m", got)
+	}
+
+	tests := []struct {
+		after      time.Duration
+		wantFast   int
+		wantSlow   int
+		wantEvents int
+	}{
+		{after: 0, wantFast: 1, wantSlow: 1, wantEvents: 2},
+		{after: 5 * time.Minute, wantFast: 1, wantSlow: 1, wantEvents: 2},
+		{after: 10 * time.Minute, wantFast: 2, wantSlow: 1, wantEvents: 2},
+		{after: time.Hour, wantFast: 3, wantSlow: 2, wantEvents: 2},
+	}
+	for _, test := range tests {
+		app.clock = fixedClock{now: now.Add(test.after)}
+
+		evnts := app.load(context.Background())
+
+		if fastRequests != test.wantFast || slowRequests != test.wantSlow {
+			t.Errorf("after %s: got %d and %d requests, want %d and %d",
+				test.after, fastRequests, slowRequests, test.wantFast, test.wantSlow)
+		}
+		if len(evnts) != test.wantEvents {
+			t.Errorf("after %s: got %d events, want %d", test.after, len(evnts), test.wantEvents)
+		}
+	}
+
+	// Clearing the cache, as a reload does, fetches every calendar again.
+	app.clearCache()
+	app.load(context.Background())
+	if fastRequests != 4 || slowRequests != 3 {
+		t.Errorf("got %d and %d requests after clearing the cache, want 4 and 3", fastRequests, slowRequests)
+	}
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+// staticEvents generates the events defined in the config.
+type staticEvents struct {
+	events []staticEvent
+	tz     *time.Location
+}
+
+type staticEvent struct {
+	cfg StaticEvent
+
+	// at is the time of a one-off event.
+	at     time.Time
+	allDay bool
+
+	// repeat is the schedule of a repeating event.
+	repeat *cronSchedule
+}
+
+func newStaticEvents(cfgs []StaticEvent, tz *time.Location) (*staticEvents, error) {
+	events := make([]staticEvent, 0, len(cfgs))
+	for i, cfg := range cfgs {
+		evnt := staticEvent{cfg: cfg}
+		switch {
+		case cfg.Repeat != "":
+			if cfg.Date != "" || cfg.Time != "" {
+				return nil, fmt.Errorf("static event %d: date and time cannot be used with repeat", i)
+			}
+			sched, err := parseCron(cfg.Repeat)
+			if err != nil {
+				return nil, fmt.Errorf("static event %d: parsing repeat: %w", i, err)
+			}
+			// All day repeats only match days, so a minute or hour
+			// would be silently ignored.
+			if cfg.AllDay && (sched.minute != cronAll(0, 59) || sched.hour != cronAll(0, 23)) {
+				return nil, fmt.Errorf("static event %d: all day repeats must use * for the minute and hour", i)
+			}
+			evnt.repeat = sched
+			evnt.allDay = cfg.AllDay
+		case cfg.Date != "":
+			if cfg.AllDay && cfg.Time != "" {
+				return nil, fmt.Errorf("static event %d: time cannot be used with allDay", i)
+			}
+			layout, value := "2006-01-02", cfg.Date
+			if cfg.Time != "" {
+				layout, value = "2006-01-02 15:04", cfg.Date+" "+cfg.Time
+			}
+			at, err := time.ParseInLocation(layout, value, tz)
+			if err != nil {
+				return nil, fmt.Errorf("static event %d: parsing date: %w", i, err)
+			}
+			evnt.at = at
+			evnt.allDay = cfg.Time == ""
+		default:
+			return nil, fmt.Errorf("static event %d: date or repeat is required", i)
+		}
+		events = append(events, evnt)
+	}
+
+	return &staticEvents{
+		events: events,
+		tz:     tz,
+	}, nil
+}
+
+func (s *staticEvents) generate(start, end time.Time) []ical.Event {
+	var evnts []ical.Event
+	for i, evnt := range s.events {
+		if evnt.repeat == nil {
+			evntEnd := evnt.at
+			if evnt.allDay {
+				evntEnd = evnt.at.AddDate(0, 0, 1)
+			}
+			if evntEnd.Before(start) || !evnt.at.Before(end) {
+				continue
+			}
+			evnts = append(evnts, evnt.event(i, evnt.at))
+			continue
+		}
+
+		if evnt.allDay {
+			for _, day := range evnt.repeat.days(start, end, s.tz) {
+				evnts = append(evnts, evnt.event(i, day))
+			}
+			continue
+		}
+		for _, t := range evnt.repeat.times(start, end, s.tz) {
+			evnts = append(evnts, evnt.event(i, t))
+		}
+	}
+	return evnts
+}
+
+func (e staticEvent) event(i int, t time.Time) ical.Event {
+	end := t
+	if e.allDay {
+		end = t.AddDate(0, 0, 1)
+	}
+	return ical.Event{
+		UID:         "static-" + strconv.Itoa(i) + "-" + t.UTC().Format("20060102T1504") + "@glasslabs-calendar",
+		Summary:     e.cfg.Title,
+		Location:    e.cfg.Location,
+		Start:       t,
+		End:         end,
+		AllDay:      e.allDay,
+		IsRecurring: e.repeat != nil,
+	}
+}
+
+// cronSchedule is a parsed five field cron expression.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// domAny and dowAny record unrestricted day fields, as a day matches
+	// either field when both are restricted.
+	domAny, dowAny bool
+}
+
+var (
+	cronMonths = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	cronDays   = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// parseCron parses a cron expression of minute, hour, day of month,
+// month and day of week fields.
+func parseCron(expr string) (*cronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields, got %d", len(fields))
+	}
+
+	var (
+		sched cronSchedule
+		err   error
+	)
+	if sched.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	if sched.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	if sched.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("day of month: %w", err)
+	}
+	if sched.month, err = parseCronField(fields[3], 1, 12, cronMonths); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	if sched.dow, err = parseCronField(fields[4], 0, 7, cronDays); err != nil {
+		return nil, fmt.Errorf("day of week: %w", err)
+	}
+	// Sunday may be given as 0 or 7.
+	if sched.dow&(1<<7) != 0 {
+		sched.dow |= 1
+	}
+	sched.domAny = fields[2] == "*"
+	sched.dowAny = fields[4] == "*"
+	return &sched, nil
+}
+
+func parseCronField(field string, lo, hi int, names []string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if r, s, ok := strings.Cut(part, "/"); ok {
+			n, err := strconv.Atoi(s)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", s)
+			}
+			rng, step = r, n
+		}
+
+		from, to := lo, hi
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if from, err = parseCronValue(a, lo, hi, names); err != nil {
+				return 0, err
+			}
+			to = from
+			if isRange {
+				if to, err = parseCronValue(b, lo, hi, names); err != nil {
+					return 0, err
+				}
+			} else if step > 1 {
+				to = hi
+			}
+			if to < from {
+				return 0, fmt.Errorf("invalid range %q", rng)
+			}
+		}
+
+		for i := from; i <= to; i += step {
+			bits |= 1 << i
+		}
+	}
+	return bits, nil
+}
+
+func parseCronValue(s string, lo, hi int, names []string) (int, error) {
+	for i, name := range names {
+		if strings.EqualFold(s, name) {
+			return i + lo, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < lo || n > hi {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	return n, nil
+}
+
+// cronAll returns the field matching every value from lo to hi.
+func cronAll(lo, hi int) uint64 {
+	var bits uint64
+	for i := lo; i <= hi; i++ {
+		bits |= 1 << i
+	}
+	return bits
+}
+
+// days returns the midnights of the days matching the schedule that
+// overlap start to end.
+func (c *cronSchedule) days(start, end time.Time, tz *time.Location) []time.Time {
+	var res []time.Time
+	y, m, d := start.In(tz).Date()
+	for day := time.Date(y, m, d, 0, 0, 0, 0, tz); day.Before(end); day = day.AddDate(0, 0, 1) {
+		if c.matchDay(day) {
+			res = append(res, day)
+		}
+	}
+	return res
+}
+
+// times returns the times matching the schedule between start and end.
+func (c *cronSchedule) times(start, end time.Time, tz *time.Location) []time.Time {
+	var res []time.Time
+	for _, day := range c.days(start, end, tz) {
+		for h := 0; h < 24; h++ {
+			if c.hour&(1<<h) == 0 {
+				continue
+			}
+			for mn := 0; mn < 60; mn++ {
+				if c.minute&(1<<mn) == 0 {
+					continue
+				}
+				t := time.Date(day.Year(), day.Month(), day.Day(), h, mn, 0, 0, tz)
+				if !t.Before(start) && t.Before(end) {
+					res = append(res, t)
+				}
+			}
+		}
+	}
+	return res
+}
+
+func (c *cronSchedule) matchDay(day time.Time) bool {
+	if c.month&(1<<int(day.Month())) == 0 {
+		return false
+	}
+	domMatch := c.dom&(1<<day.Day()) != 0
+	dowMatch := c.dow&(1<<int(day.Weekday())) != 0
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dowMatch
+	case c.dowAny:
+		return domMatch
+	default:
+		return domMatch || dowMatch
+	}
+}
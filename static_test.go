@@ -0,0 +1,176 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	bits := func(vals ...int) uint64 {
+		var b uint64
+		for _, v := range vals {
+			b |= 1 << v
+		}
+		return b
+	}
+
+	tests := []struct {
+		name    string
+		expr    string
+		want    cronSchedule
+		wantErr string
+	}{
+		{
+			name: "values",
+			expr: "30 19 1 6 2",
+			want: cronSchedule{minute: bits(30), hour: bits(19), dom: bits(1), month: bits(6), dow: bits(2)},
+		},
+		{
+			name: "any",
+			expr: "0 0 * * *",
+			want: cronSchedule{
+				minute: bits(0), hour: bits(0), dom: cronAll(1, 31), month: cronAll(1, 12), dow: cronAll(0, 7),
+				domAny: true, dowAny: true,
+			},
+		},
+		{
+			name: "ranges",
+			expr: "0 9-11 * * mon-fri",
+			want: cronSchedule{
+				minute: bits(0), hour: bits(9, 10, 11), dom: cronAll(1, 31), month: cronAll(1, 12), dow: bits(1, 2, 3, 4, 5),
+				domAny: true,
+			},
+		},
+		{
+			name: "steps",
+			expr: "*/15 8-18/5 1/10 * *",
+			want: cronSchedule{
+				minute: bits(0, 15, 30, 45), hour: bits(8, 13, 18), dom: bits(1, 11, 21, 31), month: cronAll(1, 12), dow: cronAll(0, 7),
+				dowAny: true,
+			},
+		},
+		{
+			name: "lists",
+			expr: "0,30 7,19 * jan,Jul,12 sat,0",
+			want: cronSchedule{
+				minute: bits(0, 30), hour: bits(7, 19), dom: cronAll(1, 31), month: bits(1, 7, 12), dow: bits(0, 6),
+				domAny: true,
+			},
+		},
+		{
+			name: "sunday as 7",
+			expr: "0 0 * * 7",
+			want: cronSchedule{minute: bits(0), hour: bits(0), dom: cronAll(1, 31), month: cronAll(1, 12), dow: bits(0, 7), domAny: true},
+		},
+		{name: "too few fields", expr: "0 19 * *", wantErr: "expected 5 fields, got 4"},
+		{name: "too many fields", expr: "0 0 19 * * tue", wantErr: "expected 5 fields, got 6"},
+		{name: "minute out of range", expr: "60 19 * * *", wantErr: `minute: invalid value "60"`},
+		{name: "hour not a number", expr: "0 x * * *", wantErr: `hour: invalid value "x"`},
+		{name: "zero day of month", expr: "0 0 0 * *", wantErr: `day of month: invalid value "0"`},
+		{name: "unknown month", expr: "0 0 * foo *", wantErr: `month: invalid value "foo"`},
+		{name: "reversed range", expr: "0 0 * * fri-mon", wantErr: `day of week: invalid range "fri-mon"`},
+		{name: "zero step", expr: "*/0 0 * * *", wantErr: `minute: invalid step "0"`},
+		{name: "empty list item", expr: "0, 0 * * *", wantErr: `minute: invalid value ""`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseCron(test.expr)
+
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("got error %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != test.want {
+				t.Errorf("got schedule %+v, want %+v", *got, test.want)
+			}
+		})
+	}
+}
+
+func TestNewStaticEventsRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     StaticEvent
+		wantErr string
+	}{
+		{name: "no date", cfg: StaticEvent{Title: "Bins out"}, wantErr: "date or repeat is required"},
+		{
+			name:    "time with repeat",
+			cfg:     StaticEvent{Title: "Bins out", Repeat: "0 19 * * tue", Time: "19:00"},
+			wantErr: "date and time cannot be used with repeat",
+		},
+		{
+			name:    "date with repeat",
+			cfg:     StaticEvent{Title: "Bins out", Repeat: "0 19 * * tue", Date: "2024-06-04"},
+			wantErr: "date and time cannot be used with repeat",
+		},
+		{
+			name:    "all day repeat with hour",
+			cfg:     StaticEvent{Title: "PE kit", Repeat: "* 8 * * mon", AllDay: true},
+			wantErr: "all day repeats must use * for the minute and hour",
+		},
+		{
+			name:    "all day with time",
+			cfg:     StaticEvent{Title: "Party", Date: "2024-12-31", Time: "20:00", AllDay: true},
+			wantErr: "time cannot be used with allDay",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := newStaticEvents([]StaticEvent{test.cfg}, time.UTC)
+
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("got error %v, want %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestStaticEventsGenerate(t *testing.T) {
+	tz, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := newStaticEvents([]StaticEvent{
+		{Title: "Bins out", Repeat: "0 19 * * tue"},
+		{Title: "PE kit", Repeat: "* * * * mon", AllDay: true},
+	}, tz)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Monday 3 June 2024 at noon until a week later.
+	start := time.Date(2024, 6, 3, 12, 0, 0, 0, tz)
+	evnts := s.generate(start, start.AddDate(0, 0, 7))
+
+	want := []struct {
+		summary    string
+		start, end time.Time
+		allDay     bool
+	}{
+		{summary: "Bins out", start: time.Date(2024, 6, 4, 19, 0, 0, 0, tz), end: time.Date(2024, 6, 4, 19, 0, 0, 0, tz)},
+		{summary: "PE kit", start: time.Date(2024, 6, 3, 0, 0, 0, 0, tz), end: time.Date(2024, 6, 4, 0, 0, 0, 0, tz), allDay: true},
+		{summary: "PE kit", start: time.Date(2024, 6, 10, 0, 0, 0, 0, tz), end: time.Date(2024, 6, 11, 0, 0, 0, 0, tz), allDay: true},
+	}
+	if len(evnts) != len(want) {
+		t.Fatalf("got %d events, want %d", len(evnts), len(want))
+	}
+	for i, w := range want {
+		got := evnts[i]
+		if got.Summary != w.summary || !got.Start.Equal(w.start) || !got.End.Equal(w.end) || got.AllDay != w.allDay {
+			t.Errorf("event %d: got %q from %s to %s (all day %t), want %q from %s to %s (all day %t)",
+				i, got.Summary, got.Start, got.End, got.AllDay, w.summary, w.start, w.end, w.allDay)
+		}
+		if !got.IsRecurring {
+			t.Errorf("event %d: got a one-off event, want a recurring one", i)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+const maxHistory = 100
+
+// kvStore is a persistent key value store.
+type kvStore interface {
+	Get(key string) (string, bool)
+	Set(key, val string) error
+}
+
+// snapshot is the persisted state of a source.
+type snapshot struct {
+	Fetched time.Time    `json:"fetched"`
+	Title   string       `json:"title,omitempty"`
+	Events  []ical.Event `json:"events"`
+
+	Cancelled map[string]time.Time `json:"cancelled,omitempty"`
+}
+
+// change is a change in the events of a calendar.
+type change struct {
+	Time     time.Time `json:"time"`
+	Calendar string    `json:"calendar"`
+	Type     string    `json:"type"`
+	Title    string    `json:"title"`
+	Start    time.Time `json:"start"`
+}
+
+// loadJSON decodes the value of key into v, returning false if it does
+// not exist or cannot be decoded.
+func loadJSON(s kvStore, key string, v any) bool {
+	val, ok := s.Get(key)
+	if !ok {
+		return false
+	}
+	return json.Unmarshal([]byte(val), v) == nil
+}
+
+// saveJSON stores v encoded as JSON.
+func saveJSON(s kvStore, key string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.Set(key, string(b))
+}
+
+// diffEvents returns the changes between the previous and current events.
+// Events that have ended are not reported as removed.
+func diffEvents(calendar string, now time.Time, prev, curr []ical.Event) []change {
+	key := func(e ical.Event) string {
+		return e.UID + "@" + e.Start.UTC().Format(time.RFC3339)
+	}
+
+	prevKeys := make(map[string]struct{}, len(prev))
+	for _, e := range prev {
+		prevKeys[key(e)] = struct{}{}
+	}
+	currKeys := make(map[string]struct{}, len(curr))
+
+	var changes []change
+	for _, e := range curr {
+		currKeys[key(e)] = struct{}{}
+		if _, ok := prevKeys[key(e)]; !ok {
+			changes = append(changes, change{Time: now, Calendar: calendar, Type: "added", Title: e.Summary, Start: e.Start})
+		}
+	}
+	for _, e := range prev {
+		if _, ok := currKeys[key(e)]; !ok && e.End.After(now) {
+			changes = append(changes, change{Time: now, Calendar: calendar, Type: "removed", Title: e.Summary, Start: e.Start})
+		}
+	}
+	return changes
+}
+
+// appendHistory appends the changes to the history, keeping the most recent changes.
+func appendHistory(history, changes []change) []change {
+	history = append(history, changes...)
+	if len(history) > maxHistory {
+		history = append([]change(nil), history[len(history)-maxHistory:]...)
+	}
+	return history
+}
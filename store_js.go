@@ -0,0 +1,35 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"errors"
+	"syscall/js"
+)
+
+// localStorage is a store backed by the browser local storage.
+type localStorage struct {
+	prefix string
+}
+
+// Get returns the value of the key.
+func (s localStorage) Get(key string) (string, bool) {
+	val := js.Global().Get("localStorage").Call("getItem", s.prefix+key)
+	if val.Type() != js.TypeString {
+		return "", false
+	}
+	return val.String(), true
+}
+
+// Set sets the value of the key.
+func (s localStorage) Set(key, val string) (err error) {
+	// Storing throws when the quota is exceeded.
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("storing value: quota exceeded")
+		}
+	}()
+
+	js.Global().Get("localStorage").Call("setItem", s.prefix+key, val)
+	return nil
+}
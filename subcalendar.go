@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+// newSubCalendars returns the set of sub-calendar names to keep from an
+// aggregated feed, or nil to keep all events.
+func newSubCalendars(names []string) map[string]bool {
+	if len(names) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[strings.ToLower(name)] = true
+	}
+	return set
+}
+
+// inSubCalendar reports whether the event belongs to one of the selected
+// sub-calendars of the feed. Aggregated feeds name the calendar of each
+// event in its X-WR-CALNAME property or its categories, falling back to
+// the name declared by the feed.
+func (s *source) inSubCalendar(evnt ical.Event, feed string) bool {
+	if s.only == nil {
+		return true
+	}
+
+	if name, ok := evnt.Props["X-WR-CALNAME"]; ok {
+		feed = name
+	}
+	if s.only[strings.ToLower(feed)] {
+		return true
+	}
+	for _, cat := range evnt.Categories {
+		if s.only[strings.ToLower(cat)] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// profileMessage is received on the "calendar.profile" topic.
+type profileMessage struct {
+	Module  string `json:"module"`
+	Profile string `json:"profile"`
+}
+
+// handleProfile switches to the named profile, or back to the scheduled
+// profiles when no profile is named.
+func (m *Module) handleProfile(data []byte) {
+	var msg profileMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		m.log.Error("Could not parse profile message", "error", err.Error())
+		return
+	}
+	if msg.Module != "" && msg.Module != m.mod.Name() {
+		return
+	}
+
+	if err := m.switchProfile(msg.Profile); err != nil {
+		m.log.Error("Could not switch profile", "error", err.Error())
+	}
+}
+
+// switchProfile switches to the named profile, rendering immediately.
+// The profile is persisted, so it stays active across restarts.
+func (m *Module) switchProfile(name string) error {
+	if name != "" && m.pipe.profile(name, m.clock.Now()) == nil {
+		return fmt.Errorf("unknown profile %q", name)
+	}
+
+	m.mu.Lock()
+	m.profile = name
+	if err := saveJSON(m.prefs, "profile", m.profile); err != nil {
+		m.log.Error("Could not store profile", "error", err.Error())
+	}
+	m.updateEvents()
+	m.mu.Unlock()
+
+	m.log.Info("Switched profile", "profile", name)
+	m.render()
+	return nil
+}
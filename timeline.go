@@ -0,0 +1,116 @@
+package main
+
+import (
+	"sort"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/render"
+)
+
+const (
+	timelineStart = 7 * time.Hour
+	timelineEnd   = 22 * time.Hour
+)
+
+// newTimeline returns the timeline of today's events. The timeline spans
+// the working hours if set, extended to whole hours containing all events.
+func newTimeline(events []render.Event, now time.Time, hours *workingHours) *render.Timeline {
+	y, m, d := now.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+
+	from, to := atTimeOfDay(day, timelineStart), atTimeOfDay(day, timelineEnd)
+	if hours != nil {
+		from, to = atTimeOfDay(day, hours.start), atTimeOfDay(day, hours.end)
+	}
+
+	tl := &render.Timeline{}
+	type span struct {
+		evnt       render.Event
+		start, end time.Time
+	}
+	var spans []span
+	for _, evnt := range events {
+		if evnt.IsAllDay {
+			tl.AllDay = append(tl.AllDay, evnt)
+			continue
+		}
+
+		end := evnt.End
+		if !end.After(evnt.Time) {
+			end = evnt.Time.Add(15 * time.Minute)
+		}
+		if dayEnd := day.AddDate(0, 0, 1); end.After(dayEnd) {
+			end = dayEnd
+		}
+		spans = append(spans, span{evnt: evnt, start: evnt.Time, end: end})
+
+		if evnt.Time.Before(from) {
+			from = evnt.Time.Truncate(time.Hour)
+		}
+		if end.After(to) {
+			to = end.Add(time.Hour - time.Nanosecond).Truncate(time.Hour)
+		}
+	}
+	total := to.Sub(from)
+	pos := func(t time.Time) float64 {
+		return 100 * float64(t.Sub(from)) / float64(total)
+	}
+
+	for t := from.Truncate(time.Hour); !t.After(to); t = t.Add(time.Hour) {
+		if t.Before(from) {
+			continue
+		}
+		tl.Hours = append(tl.Hours, render.TimelineHour{Time: t, Top: pos(t)})
+	}
+
+	// Overlapping events are placed side by side in columns, with each
+	// group of overlapping events sharing the width.
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start.Before(spans[j].start) })
+	var (
+		group    []render.TimelineBlock
+		colEnds  []time.Time
+		groupEnd time.Time
+	)
+	flush := func() {
+		for i := range group {
+			group[i].Width = 100 / float64(len(colEnds))
+			group[i].Left = group[i].Left * group[i].Width
+		}
+		tl.Blocks = append(tl.Blocks, group...)
+		group, colEnds = nil, nil
+	}
+	for _, s := range spans {
+		if len(group) > 0 && !s.start.Before(groupEnd) {
+			flush()
+		}
+		col := -1
+		for c, end := range colEnds {
+			if !s.start.Before(end) {
+				col = c
+				break
+			}
+		}
+		if col < 0 {
+			col = len(colEnds)
+			colEnds = append(colEnds, time.Time{})
+		}
+		colEnds[col] = s.end
+		if s.end.After(groupEnd) || len(group) == 0 {
+			groupEnd = s.end
+		}
+
+		group = append(group, render.TimelineBlock{
+			Event:  s.evnt,
+			Top:    pos(s.start),
+			Height: pos(s.end) - pos(s.start),
+			Left:   float64(col),
+		})
+	}
+	flush()
+
+	tl.Now = -1
+	if !now.Before(from) && now.Before(to) {
+		tl.Now = pos(now)
+	}
+	return tl
+}
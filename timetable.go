@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+// timetable generates events from weekly timetable slots.
+type timetable struct {
+	name  string
+	slots []timetableSlot
+	tz    *time.Location
+}
+
+type timetableSlot struct {
+	cfg        Slot
+	day        time.Weekday
+	start, end time.Duration
+}
+
+func newTimetable(cfg Timetable, tz *time.Location) (*timetable, error) {
+	slots := make([]timetableSlot, 0, len(cfg.Slots))
+	for i, s := range cfg.Slots {
+		day, err := parseWeekday(s.Day)
+		if err != nil {
+			return nil, fmt.Errorf("timetable %q slot %d: %w", cfg.Name, i, err)
+		}
+		start, err := parseTimeOfDay(s.Start)
+		if err != nil {
+			return nil, fmt.Errorf("timetable %q slot %d: parsing start: %w", cfg.Name, i, err)
+		}
+		end := start
+		if s.End != "" {
+			if end, err = parseTimeOfDay(s.End); err != nil {
+				return nil, fmt.Errorf("timetable %q slot %d: parsing end: %w", cfg.Name, i, err)
+			}
+		}
+		slots = append(slots, timetableSlot{
+			cfg:   s,
+			day:   day,
+			start: start,
+			end:   end,
+		})
+	}
+
+	return &timetable{
+		name:  cfg.Name,
+		slots: slots,
+		tz:    tz,
+	}, nil
+}
+
+func (t *timetable) generate(start, end time.Time) []ical.Event {
+	var evnts []ical.Event
+	y, m, d := start.In(t.tz).Date()
+	for day := time.Date(y, m, d, 0, 0, 0, 0, t.tz); day.Before(end); day = day.AddDate(0, 0, 1) {
+		for i, s := range t.slots {
+			if s.day != day.Weekday() {
+				continue
+			}
+			slotStart := atTimeOfDay(day, s.start)
+			slotEnd := atTimeOfDay(day, s.end)
+			if slotEnd.Before(start) || !slotStart.Before(end) {
+				continue
+			}
+			evnts = append(evnts, ical.Event{
+				UID:         "timetable-" + t.name + "-" + strconv.Itoa(i) + "-" + day.Format("20060102") + "@glasslabs-calendar",
+				Summary:     s.cfg.Title,
+				Location:    s.cfg.Location,
+				Start:       slotStart,
+				End:         slotEnd,
+				IsRecurring: true,
+			})
+		}
+	}
+	return evnts
+}
+
+// parseWeekday parses a weekday name or its three letter abbreviation.
+func parseWeekday(s string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := d.String()
+		if strings.EqualFold(s, name) || strings.EqualFold(s, name[:3]) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid day %q", s)
+}
+
+// parseTimeOfDay parses a time in the form 15:04 as the duration since midnight.
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// atTimeOfDay returns the wall clock time of day on the given day.
+func atTimeOfDay(day time.Time, d time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, int(d/time.Minute), 0, 0, day.Location())
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+// kindTask is the kind of events that are tasks.
+const kindTask = ical.KindTask
+
+// todoistSource loads the Todoist tasks with a due date as task events.
+type todoistSource struct {
+	url   string
+	token string
+	query string
+	f     *ical.Fetcher
+	tz    *time.Location
+}
+
+func newTodoistSource(cal Calendar, f *ical.Fetcher, tz *time.Location) (*todoistSource, error) {
+	if cal.Auth.Token == "" {
+		return nil, errors.New("todoist calendar token is required")
+	}
+	return &todoistSource{
+		url:   strings.TrimSuffix(cal.URL, "/"),
+		token: cal.Auth.Token,
+		query: cal.Query,
+		f:     f,
+		tz:    tz,
+	}, nil
+}
+
+type todoistTask struct {
+	ID          string `json:"id"`
+	Content     string `json:"content"`
+	Description string `json:"description"`
+	Due         *struct {
+		Date string `json:"date"`
+	} `json:"due"`
+	Duration *struct {
+		Amount int    `json:"amount"`
+		Unit   string `json:"unit"`
+	} `json:"duration"`
+}
+
+func (s *todoistSource) events(ctx context.Context, start, end time.Time) ([]ical.Event, error) {
+	tasks, err := s.tasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var evnts []ical.Event
+	for _, task := range tasks {
+		if task.Due == nil {
+			continue
+		}
+		evnt, ok, err := s.taskEvent(task, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("task %s: %w", task.ID, err)
+		}
+		if !ok {
+			continue
+		}
+		evnt.UID = "todoist-" + task.ID + "@glasslabs-calendar"
+		evnt.Summary = task.Content
+		evnt.Description = task.Description
+		evnt.Kind = kindTask
+		evnts = append(evnts, evnt)
+	}
+	return evnts, nil
+}
+
+// taskEvent returns the event of the task's due date or time, if it is
+// within the window between start and end.
+func (s *todoistSource) taskEvent(task todoistTask, start, end time.Time) (ical.Event, bool, error) {
+	if len(task.Due.Date) == len(time.DateOnly) {
+		due, err := time.Parse(time.DateOnly, task.Due.Date)
+		if err != nil {
+			return ical.Event{}, false, fmt.Errorf("parsing due date: %w", err)
+		}
+		evnt, ok := allDayEvent(due, s.tz, start, end)
+		return evnt, ok, nil
+	}
+
+	// Due times without a UTC offset are floating, in the local timezone.
+	var (
+		due time.Time
+		err error
+	)
+	if strings.HasSuffix(task.Due.Date, "Z") {
+		due, err = time.Parse(time.RFC3339, task.Due.Date)
+	} else {
+		due, err = time.ParseInLocation("2006-01-02T15:04:05", task.Due.Date, s.tz)
+	}
+	if err != nil {
+		return ical.Event{}, false, fmt.Errorf("parsing due time: %w", err)
+	}
+
+	dueEnd := due
+	if task.Duration != nil {
+		switch task.Duration.Unit {
+		case "minute":
+			dueEnd = due.Add(time.Duration(task.Duration.Amount) * time.Minute)
+		case "day":
+			dueEnd = due.AddDate(0, 0, task.Duration.Amount)
+		}
+	}
+	if dueEnd.Before(start) || !due.Before(end) {
+		return ical.Event{}, false, nil
+	}
+	return ical.Event{Start: due, End: dueEnd}, true, nil
+}
+
+// complete closes the task of the event with the given uid.
+func (s *todoistSource) complete(ctx context.Context, uid string) error {
+	id, ok := strings.CutPrefix(uid, "todoist-")
+	id, _, found := strings.Cut(id, "@")
+	if !ok || !found || id == "" {
+		return fmt.Errorf("%q is not a todoist task", uid)
+	}
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+s.token)
+	if _, err := s.f.Request(ctx, http.MethodPost, s.url+"/api/v1/tasks/"+url.PathEscape(id)+"/close", header, nil); err != nil {
+		return fmt.Errorf("closing task: %w", err)
+	}
+	return nil
+}
+
+// tasks returns all active tasks matching the filter query, following
+// the pages of results.
+func (s *todoistSource) tasks(ctx context.Context) ([]todoistTask, error) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+s.token)
+
+	var tasks []todoistTask
+	cursor := ""
+	for {
+		q := url.Values{}
+		q.Set("limit", "200")
+		if cursor != "" {
+			q.Set("cursor", cursor)
+		}
+		path := "/api/v1/tasks"
+		if s.query != "" {
+			path += "/filter"
+			q.Set("query", s.query)
+		}
+
+		b, err := s.f.Request(ctx, http.MethodGet, s.url+path+"?"+q.Encode(), header, nil)
+		if err != nil {
+			return nil, fmt.Errorf("fetching tasks: %w", err)
+		}
+		var page struct {
+			Results    []todoistTask `json:"results"`
+			NextCursor *string       `json:"next_cursor"`
+		}
+		if err = json.Unmarshal(b, &page); err != nil {
+			return nil, fmt.Errorf("parsing response: %w", err)
+		}
+		tasks = append(tasks, page.Results...)
+
+		if page.NextCursor == nil || *page.NextCursor == "" {
+			return tasks, nil
+		}
+		cursor = *page.NextCursor
+	}
+}
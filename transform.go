@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+// transformerFactories are the built-in transformers selectable by config.
+var transformerFactories = map[string]func(cfg Transform) (ical.EventTransformer, error){
+	"exclude": func(cfg Transform) (ical.EventTransformer, error) {
+		re, err := regexp.Compile(cfg.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("parsing pattern: %w", err)
+		}
+		return ical.Exclude(re), nil
+	},
+	"include": func(cfg Transform) (ical.EventTransformer, error) {
+		re, err := regexp.Compile(cfg.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("parsing pattern: %w", err)
+		}
+		return ical.Include(re), nil
+	},
+	"rewrite": func(cfg Transform) (ical.EventTransformer, error) {
+		re, err := regexp.Compile(cfg.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("parsing pattern: %w", err)
+		}
+		return ical.Rewrite(re, cfg.Replace), nil
+	},
+	"dedup": func(Transform) (ical.EventTransformer, error) {
+		return ical.Dedup(), nil
+	},
+}
+
+// newTransformers returns the chain of configured transformers.
+func newTransformers(cfgs []Transform) (ical.Chain, error) {
+	chain := make(ical.Chain, 0, len(cfgs))
+	for i, cfg := range cfgs {
+		factory, ok := transformerFactories[cfg.Type]
+		if !ok {
+			return nil, fmt.Errorf("transform %d: unknown type %q", i, cfg.Type)
+		}
+		t, err := factory(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("transform %d: %w", i, err)
+		}
+		chain = append(chain, t)
+	}
+	return chain, nil
+}
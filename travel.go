@@ -0,0 +1,191 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+	"github.com/glasslabs/calendar/pkg/render"
+)
+
+// routeFunc returns the travel time from home to the destination
+// when departing at the given time.
+type routeFunc func(ctx context.Context, dest string, depart time.Time) (time.Duration, error)
+
+// travelPlanner computes leave by times for events with a location,
+// caching the travel time of each event.
+type travelPlanner struct {
+	cfg   Travel
+	f     *ical.Fetcher
+	route routeFunc
+
+	mu    sync.Mutex
+	times map[string]time.Duration
+}
+
+func newTravelPlanner(cfg Travel, f *ical.Fetcher) (*travelPlanner, error) {
+	t := &travelPlanner{
+		cfg:   cfg,
+		f:     f,
+		times: map[string]time.Duration{},
+	}
+	switch cfg.Provider {
+	case "osrm":
+		t.route = t.routeOSRM
+		t.cfg.URL = cmp.Or(cfg.URL, "https://router.project-osrm.org")
+		t.cfg.GeocodeURL = cmp.Or(cfg.GeocodeURL, "https://nominatim.openstreetmap.org")
+	case "google":
+		if cfg.APIKey == "" {
+			return nil, errors.New("travel api key is required for google")
+		}
+		t.route = t.routeGoogle
+		t.cfg.URL = cmp.Or(cfg.URL, "https://maps.googleapis.com")
+	default:
+		return nil, fmt.Errorf("unknown travel provider %q", cfg.Provider)
+	}
+	t.cfg.Profile = cmp.Or(cfg.Profile, "driving")
+	return t, nil
+}
+
+// update fetches the travel times of upcoming timed events with a location
+// that are not yet cached, dropping the times of events no longer shown.
+func (t *travelPlanner) update(ctx context.Context, now time.Time, events []render.Event) []error {
+	t.mu.Lock()
+	keep := make(map[string]time.Duration, len(events))
+	var pending []render.Event
+	for _, evnt := range events {
+		if evnt.IsAllDay || evnt.Location == "" || !evnt.Time.After(now) || evnt.Time.Sub(now) > t.cfg.Lookahead {
+			continue
+		}
+		if d, ok := t.times[travelKey(evnt)]; ok {
+			keep[travelKey(evnt)] = d
+			continue
+		}
+		pending = append(pending, evnt)
+	}
+	t.times = keep
+	t.mu.Unlock()
+
+	var errs []error
+	for _, evnt := range pending {
+		d, err := t.route(ctx, evnt.Location, evnt.Time)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("routing to %q: %w", evnt.Location, err))
+			continue
+		}
+
+		t.mu.Lock()
+		t.times[travelKey(evnt)] = d
+		t.mu.Unlock()
+	}
+	return errs
+}
+
+// leaveBy returns the time to leave by to arrive for the event, if known.
+func (t *travelPlanner) leaveBy(evnt render.Event) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	d, ok := t.times[travelKey(evnt)]
+	if !ok {
+		return time.Time{}, false
+	}
+	return evnt.Time.Add(-d), true
+}
+
+func travelKey(evnt render.Event) string {
+	return evnt.ID + "\x00" + evnt.Location
+}
+
+func (t *travelPlanner) origin() string {
+	return strconv.FormatFloat(t.cfg.Latitude, 'f', -1, 64) + "," + strconv.FormatFloat(t.cfg.Longitude, 'f', -1, 64)
+}
+
+// routeOSRM geocodes the destination with Nominatim, then routes to it with OSRM.
+func (t *travelPlanner) routeOSRM(ctx context.Context, dest string, _ time.Time) (time.Duration, error) {
+	b, err := t.f.Fetch(ctx, t.cfg.GeocodeURL+"/search?format=json&limit=1&q="+url.QueryEscape(dest))
+	if err != nil {
+		return 0, fmt.Errorf("geocoding: %w", err)
+	}
+	var places []struct {
+		Lat string `json:"lat"`
+		Lon string `json:"lon"`
+	}
+	if err = json.Unmarshal(b, &places); err != nil {
+		return 0, fmt.Errorf("parsing geocoding response: %w", err)
+	}
+	if len(places) == 0 {
+		return 0, errors.New("location not found")
+	}
+
+	coords := strconv.FormatFloat(t.cfg.Longitude, 'f', -1, 64) + "," + strconv.FormatFloat(t.cfg.Latitude, 'f', -1, 64) +
+		";" + places[0].Lon + "," + places[0].Lat
+	b, err = t.f.Fetch(ctx, t.cfg.URL+"/route/v1/"+t.cfg.Profile+"/"+coords+"?overview=false")
+	if err != nil {
+		return 0, err
+	}
+	var resp struct {
+		Code   string `json:"code"`
+		Routes []struct {
+			Duration float64 `json:"duration"`
+		} `json:"routes"`
+	}
+	if err = json.Unmarshal(b, &resp); err != nil {
+		return 0, fmt.Errorf("parsing route response: %w", err)
+	}
+	if resp.Code != "Ok" || len(resp.Routes) == 0 {
+		return 0, fmt.Errorf("no route: %s", resp.Code)
+	}
+	return time.Duration(resp.Routes[0].Duration * float64(time.Second)), nil
+}
+
+// routeGoogle routes to the destination with the Google Distance Matrix API,
+// taking traffic at the departure time into account.
+func (t *travelPlanner) routeGoogle(ctx context.Context, dest string, depart time.Time) (time.Duration, error) {
+	q := url.Values{}
+	q.Set("origins", t.origin())
+	q.Set("destinations", dest)
+	q.Set("mode", t.cfg.Profile)
+	q.Set("departure_time", strconv.FormatInt(depart.Unix(), 10))
+	q.Set("key", t.cfg.APIKey)
+	b, err := t.f.Fetch(ctx, t.cfg.URL+"/maps/api/distancematrix/json?"+q.Encode())
+	if err != nil {
+		return 0, err
+	}
+
+	type value struct {
+		Value float64 `json:"value"`
+	}
+	var resp struct {
+		Status string `json:"status"`
+		Rows   []struct {
+			Elements []struct {
+				Status            string `json:"status"`
+				Duration          *value `json:"duration"`
+				DurationInTraffic *value `json:"duration_in_traffic"`
+			} `json:"elements"`
+		} `json:"rows"`
+	}
+	if err = json.Unmarshal(b, &resp); err != nil {
+		return 0, fmt.Errorf("parsing route response: %w", err)
+	}
+	if resp.Status != "OK" || len(resp.Rows) == 0 || len(resp.Rows[0].Elements) == 0 {
+		return 0, fmt.Errorf("no route: %s", resp.Status)
+	}
+	elem := resp.Rows[0].Elements[0]
+	switch {
+	case elem.DurationInTraffic != nil:
+		return time.Duration(elem.DurationInTraffic.Value * float64(time.Second)), nil
+	case elem.Duration != nil:
+		return time.Duration(elem.Duration.Value * float64(time.Second)), nil
+	default:
+		return 0, fmt.Errorf("no route: %s", elem.Status)
+	}
+}
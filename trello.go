@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/glasslabs/calendar/pkg/ical"
+)
+
+// trelloSource loads the incomplete cards with a due date of Trello
+// boards as task events.
+type trelloSource struct {
+	url    string
+	key    string
+	token  string
+	boards []string
+	lists  []string
+	f      *ical.Fetcher
+}
+
+func newTrelloSource(cal Calendar, f *ical.Fetcher) (*trelloSource, error) {
+	if cal.Auth.Key == "" || cal.Auth.Token == "" {
+		return nil, errors.New("trello calendar key and token are required")
+	}
+	if len(cal.Boards) == 0 {
+		return nil, errors.New("trello calendar boards are required")
+	}
+	return &trelloSource{
+		url:    strings.TrimSuffix(cal.URL, "/"),
+		key:    cal.Auth.Key,
+		token:  cal.Auth.Token,
+		boards: cal.Boards,
+		lists:  cal.Lists,
+		f:      f,
+	}, nil
+}
+
+type trelloCard struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Desc        string     `json:"desc"`
+	ShortURL    string     `json:"shortUrl"`
+	IDList      string     `json:"idList"`
+	Due         *time.Time `json:"due"`
+	DueComplete bool       `json:"dueComplete"`
+}
+
+type trelloList struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (s *trelloSource) events(ctx context.Context, start, end time.Time) ([]ical.Event, error) {
+	var evnts []ical.Event
+	for _, board := range s.boards {
+		var lists []trelloList
+		if err := s.get(ctx, "/1/boards/"+url.PathEscape(board)+"/lists", "id,name", &lists); err != nil {
+			return nil, fmt.Errorf("fetching lists of board %q: %w", board, err)
+		}
+		names := make(map[string]string, len(lists))
+		for _, l := range lists {
+			names[l.ID] = l.Name
+		}
+
+		var cards []trelloCard
+		if err := s.get(ctx, "/1/boards/"+url.PathEscape(board)+"/cards", "name,desc,shortUrl,idList,due,dueComplete", &cards); err != nil {
+			return nil, fmt.Errorf("fetching cards of board %q: %w", board, err)
+		}
+		for _, card := range cards {
+			if card.Due == nil || card.DueComplete || card.Due.Before(start) || !card.Due.Before(end) {
+				continue
+			}
+			list := names[card.IDList]
+			if len(s.lists) > 0 && !slices.Contains(s.lists, list) && !slices.Contains(s.lists, card.IDList) {
+				continue
+			}
+
+			evnts = append(evnts, ical.Event{
+				UID:         "trello-" + card.ID + "@glasslabs-calendar",
+				Kind:        kindTask,
+				Summary:     card.Name,
+				Location:    list,
+				Description: strings.TrimSpace(card.Desc + "\n" + card.ShortURL),
+				Start:       *card.Due,
+				End:         *card.Due,
+			})
+		}
+	}
+	return evnts, nil
+}
+
+// get decodes the JSON response of the API path, with the given fields,
+// into v.
+func (s *trelloSource) get(ctx context.Context, path, fields string, v any) error {
+	q := url.Values{}
+	q.Set("fields", fields)
+	q.Set("key", s.key)
+	q.Set("token", s.token)
+
+	b, err := s.f.Request(ctx, http.MethodGet, s.url+path+"?"+q.Encode(), nil, nil)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
+	}
+	return nil
+}
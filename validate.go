@@ -0,0 +1,140 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"time"
+)
+
+// Validate checks the configuration, reporting all problems at once.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	checkTimezone := func(prefix, name string) {
+		if name == "" {
+			return
+		}
+		if _, err := time.LoadLocation(name); err != nil {
+			errs = append(errs, fmt.Errorf("%sunknown timezone %q", prefix, name))
+		}
+	}
+
+	checkTimezone("", c.Timezone)
+	checkTimezone("secondaryTimezone: ", c.SecondaryTimezone)
+	if rawURL := c.DetectTimezone.GeoIPURL; rawURL != "" {
+		u, err := url.Parse(rawURL)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"detectTimezone: invalid geoIpUrl %q", rawURL)
+	}
+	if c.MQTT.URL != "" {
+		check(c.MQTT.KeepAlive >= time.Second && c.MQTT.KeepAlive <= math.MaxUint16*time.Second,
+			"mqtt.keepAlive must be between 1s and 18h12m15s, got %s", c.MQTT.KeepAlive)
+	}
+	check(c.MaxDays > 0, "maxDays must be positive, got %d", c.MaxDays)
+	check(c.MaxEvents >= 0, "maxEvents must not be negative, got %d", c.MaxEvents)
+	check(c.Interval > 0, "interval must be positive, got %s", c.Interval)
+	check(c.Timeout > 0, "timeout must be positive, got %s", c.Timeout)
+	check(c.BackoffAfter >= 0, "backoffAfter must not be negative, got %d", c.BackoffAfter)
+	check(c.MaxFeedSize >= 0, "maxFeedSize must not be negative, got %d", c.MaxFeedSize)
+	check(c.MaxRedirects >= 0, "maxRedirects must not be negative, got %d", c.MaxRedirects)
+	check(c.RateLimit >= 0, "rateLimit must not be negative, got %v", c.RateLimit)
+	check(c.Scale > 0, "scale must be positive, got %v", c.Scale)
+	check(c.RibbonHours > 0, "ribbonHours must be positive, got %d", c.RibbonHours)
+	check(c.ExpandTimeout >= 0, "expandTimeout must not be negative, got %s", c.ExpandTimeout)
+	if len(c.Views) > 1 {
+		check(c.RotateInterval > 0, "rotateInterval must be positive, got %s", c.RotateInterval)
+	}
+	for _, view := range append([]string{c.View}, c.Views...) {
+		if err := validateView(view); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := validatePageIndicator(c.PageIndicator); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateProfile(c.DisplayProfile); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateUnits(c.Home.Units); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := newClock(c.Fixture); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := newProfiles(c.Profiles); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := newStaticEvents(c.StaticEvents, time.UTC); err != nil {
+		errs = append(errs, err)
+	}
+	if c.Alarms.Show || c.Alarms.Publish {
+		check(c.Alarms.Timeout > 0, "alarms.timeout must be positive, got %s", c.Alarms.Timeout)
+	}
+	if c.chimesEnabled() {
+		check(c.Chime.Topic != "", "chime.topic is required")
+	}
+
+	for i, cal := range c.Calendars {
+		prefix := fmt.Sprintf("calendars[%d]: ", i)
+		if name := calendarName(cal); name != "" {
+			prefix = fmt.Sprintf("calendars[%d] (%s): ", i, name)
+		}
+
+		checkTimezone(prefix, cal.Timezone)
+		check(cal.MaxEvents >= 0, "%smaxEvents must not be negative, got %d", prefix, cal.MaxEvents)
+		check(cal.Timeout >= 0, "%stimeout must not be negative, got %s", prefix, cal.Timeout)
+		check(cal.Interval >= 0, "%sinterval must not be negative, got %s", prefix, cal.Interval)
+
+		switch cal.Type {
+		case "", calendarICloud, calendarGitHub, calendarJira, calendarTodoist, calendarTrello:
+		default:
+			errs = append(errs, fmt.Errorf("%sunknown calendar type %q", prefix, cal.Type))
+			continue
+		}
+		if len(cal.OnlyCalendars) > 0 {
+			check(cal.Type == "" || cal.Type == calendarICloud,
+				"%sonlyCalendars is not supported for %s calendars", prefix, cal.Type)
+			for _, name := range cal.OnlyCalendars {
+				check(name != "", "%sonlyCalendars must not contain empty names", prefix)
+			}
+		}
+		if cal.CompleteTasks {
+			check(cal.Type == calendarTodoist || cal.Type == calendarICloud,
+				"%scompleteTasks is only supported for todoist and icloud calendars", prefix)
+		}
+		if cal.QuickAdd {
+			check(cal.Type == calendarICloud, "%squickAdd is only supported for icloud calendars", prefix)
+		}
+		if err := validateAuth(cal); err != nil {
+			errs = append(errs, fmt.Errorf("%s%w", prefix, err))
+		}
+
+		rawURL := cmp.Or(cal.URL, defaultURLs[cal.Type])
+		if rawURL == "" {
+			errs = append(errs, fmt.Errorf("%surl is required", prefix))
+			continue
+		}
+		u, err := url.Parse(rawURL)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("%sinvalid url %q", prefix, rawURL))
+		case u.Scheme == "file":
+			if err = validateFixtureURL(c.Fixture, cal, u); err != nil {
+				errs = append(errs, fmt.Errorf("%s%w", prefix, err))
+			}
+		case u.Scheme != "http" && u.Scheme != "https":
+			errs = append(errs, fmt.Errorf("%surl scheme must be http or https, got %q", prefix, u.Scheme))
+		case u.Host == "":
+			errs = append(errs, fmt.Errorf("%surl %q has no host", prefix, rawURL))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,139 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"syscall/js"
+)
+
+// wsConn reads and writes binary messages over a browser WebSocket as
+// a stream of bytes.
+type wsConn struct {
+	ws    js.Value
+	funcs map[string]js.Func
+
+	mu     sync.Mutex
+	buf    []byte
+	closed bool
+
+	// ready is signalled when data is received or the socket closes.
+	ready chan struct{}
+}
+
+// dialWebSocket opens a WebSocket using the MQTT sub-protocol.
+func dialWebSocket(ctx context.Context, url string) (io.ReadWriteCloser, error) {
+	ws := js.Global().Get("WebSocket").New(url, "mqtt")
+	ws.Set("binaryType", "arraybuffer")
+
+	conn := &wsConn{ws: ws, ready: make(chan struct{}, 1)}
+	opened := make(chan struct{}, 1)
+	failed := make(chan struct{}, 1)
+	onOpen := js.FuncOf(func(js.Value, []js.Value) any {
+		opened <- struct{}{}
+		return nil
+	})
+	onError := js.FuncOf(func(js.Value, []js.Value) any {
+		select {
+		case failed <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	onMessage := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		arr := js.Global().Get("Uint8Array").New(args[0].Get("data"))
+		b := make([]byte, arr.Get("length").Int())
+		js.CopyBytesToGo(b, arr)
+
+		conn.mu.Lock()
+		conn.buf = append(conn.buf, b...)
+		conn.mu.Unlock()
+		conn.signal()
+		return nil
+	})
+	onClose := js.FuncOf(func(js.Value, []js.Value) any {
+		conn.mu.Lock()
+		conn.closed = true
+		conn.mu.Unlock()
+		conn.signal()
+		return nil
+	})
+	conn.funcs = map[string]js.Func{"open": onOpen, "error": onError, "message": onMessage, "close": onClose}
+	for typ, fn := range conn.funcs {
+		ws.Call("addEventListener", typ, fn)
+	}
+
+	select {
+	case <-ctx.Done():
+		_ = conn.Close()
+		return nil, ctx.Err()
+	case <-failed:
+		_ = conn.Close()
+		return nil, errors.New("websocket connection failed")
+	case <-opened:
+		return conn, nil
+	}
+}
+
+// signal wakes a blocked read.
+func (c *wsConn) signal() {
+	select {
+	case c.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Read reads the data of received messages, blocking until data is
+// received or the socket closes.
+func (c *wsConn) Read(p []byte) (int, error) {
+	for {
+		c.mu.Lock()
+		if len(c.buf) > 0 {
+			n := copy(p, c.buf)
+			c.buf = c.buf[n:]
+			c.mu.Unlock()
+			return n, nil
+		}
+		closed := c.closed
+		c.mu.Unlock()
+
+		if closed {
+			return 0, io.EOF
+		}
+		<-c.ready
+	}
+}
+
+// Write sends p as a single binary message.
+func (c *wsConn) Write(p []byte) (int, error) {
+	const stateOpen = 1
+	if c.ws.Get("readyState").Int() != stateOpen {
+		return 0, errors.New("websocket is not open")
+	}
+
+	arr := js.Global().Get("Uint8Array").New(len(p))
+	js.CopyBytesToJS(arr, p)
+	c.ws.Call("send", arr)
+	return len(p), nil
+}
+
+// Close closes the WebSocket.
+func (c *wsConn) Close() error {
+	c.ws.Call("close")
+
+	c.mu.Lock()
+	c.closed = true
+	funcs := c.funcs
+	c.funcs = nil
+	c.mu.Unlock()
+	c.signal()
+
+	for typ, fn := range funcs {
+		c.ws.Call("removeEventListener", typ, fn)
+		fn.Release()
+	}
+	return nil
+}
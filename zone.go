@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// detectTimezone sets the timezone to the timezone of the host when
+// no timezone is configured and detection is enabled. Hosts in UTC, as
+// most containers are, fall back to a geo-IP lookup when configured.
+func (c *Config) detectTimezone(ctx context.Context) error {
+	if c.Timezone != "" || !c.DetectTimezone.Enabled {
+		return nil
+	}
+
+	name := hostTimezone()
+	if c.DetectTimezone.GeoIPURL != "" && isUTC(name) {
+		ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+
+		var err error
+		if name, err = lookupTimezone(ctx, newFetcher(*c).Fetch, c.DetectTimezone.GeoIPURL); err != nil {
+			return err
+		}
+	}
+	if name == "" {
+		return errors.New("timezone could not be detected")
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return fmt.Errorf("unknown detected timezone %q", name)
+	}
+	c.Timezone = name
+	return nil
+}
+
+// lookupTimezone returns the timezone from a geo-IP service, answering
+// either with the timezone name as text or a JSON object with a
+// "timezone" field.
+func lookupTimezone(ctx context.Context, fetch func(context.Context, string) ([]byte, error), rawURL string) (string, error) {
+	b, err := fetch(ctx, rawURL)
+	if err != nil {
+		return "", fmt.Errorf("looking up timezone: %w", err)
+	}
+
+	body := strings.TrimSpace(string(b))
+	if !strings.HasPrefix(body, "{") {
+		return body, nil
+	}
+	var resp struct {
+		Timezone string `json:"timezone"`
+	}
+	if err = json.Unmarshal(b, &resp); err != nil {
+		return "", fmt.Errorf("decoding timezone: %w", err)
+	}
+	return resp.Timezone, nil
+}
+
+// isUTC reports whether the timezone name is unknown or UTC.
+func isUTC(name string) bool {
+	switch name {
+	case "", "UTC", "Etc/UTC", "Etc/GMT", "GMT":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,18 @@
+//go:build js && wasm
+
+package main
+
+import "syscall/js"
+
+// hostTimezone returns the timezone of the browser.
+func hostTimezone() string {
+	intl := js.Global().Get("Intl")
+	if intl.Type() != js.TypeObject {
+		return ""
+	}
+	zone := intl.Call("DateTimeFormat").Call("resolvedOptions").Get("timeZone")
+	if zone.Type() != js.TypeString {
+		return ""
+	}
+	return zone.String()
+}